@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+
+	app "github.com/danvixent/buycoin-challenge2"
+)
+
+type contextKey struct{}
+
+type tokenErrorKey struct{}
+
+// WithSession returns a copy of ctx carrying the session a request was authenticated with,
+// along with its user
+func WithSession(ctx context.Context, session *app.Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, session)
+}
+
+// SessionFromContext returns the session a request was authenticated with, or nil for anonymous requests
+func SessionFromContext(ctx context.Context) *app.Session {
+	session, _ := ctx.Value(contextKey{}).(*app.Session)
+	return session
+}
+
+// UserFromContext returns the user authenticated for the request, or nil for anonymous requests
+func UserFromContext(ctx context.Context) *app.User {
+	session := SessionFromContext(ctx)
+	if session == nil {
+		return nil
+	}
+	return session.User
+}
+
+// WithTokenError returns a copy of ctx recording why the request's token was rejected, the
+// request carries on anonymously so operations that don't need authentication still work
+func WithTokenError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, tokenErrorKey{}, err)
+}
+
+// TokenErrorFromContext returns why the request's token was rejected, or nil when it was accepted or missing
+func TokenErrorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(tokenErrorKey{}).(error)
+	return err
+}
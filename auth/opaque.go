@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const opaqueTokenBytes = 32
+
+// NewOpaqueToken returns a random URL safe token that carries no claims, for tokens like refresh
+// tokens that are looked up in the database rather than verified by signature
+func NewOpaqueToken() (string, error) {
+	token := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashOpaqueToken returns the hex encoded SHA-256 of an opaque token to store in its place. A
+// salted slow hash isn't needed since the token is 256 random bits, not something a user chose
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTokenExpiry        = 15 * time.Minute
+	defaultRefreshTokenExpiry = 30 * 24 * time.Hour
+	// minKeyLength is the size of the SHA-256 output, shorter HMAC keys are easier to brute force
+	minKeyLength = 32
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed, signed with an unknown key or tampered with
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned for tokens that were valid but have expired
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims are carried by the tokens issued on login, the subject is the user's ID
+type Claims struct {
+	SessionID string `json:"sid"`
+	jwt.StandardClaims
+}
+
+// TokenManager issues and checks the signed JWTs users authenticate with
+type TokenManager struct {
+	issuer        string
+	expiry        time.Duration
+	refreshExpiry time.Duration
+	signingKey    config.SigningKey
+	keys          map[string][]byte
+}
+
+// SigningKeysEnv names the environment variable signing keys are read from, so their secrets stay
+// out of the config file
+const SigningKeysEnv = "AUTH_SIGNING_KEYS"
+
+// ParseSigningKeys reads a comma separated list of id:secret pairs, in the order they're listed
+func ParseSigningKeys(value string) ([]config.SigningKey, error) {
+	var keys []config.SigningKey
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		i := strings.Index(pair, ":")
+		if i < 0 {
+			return nil, errors.New("signing keys must be listed as id:secret")
+		}
+		keys = append(keys, config.SigningKey{ID: pair[:i], Secret: pair[i+1:]})
+	}
+	return keys, nil
+}
+
+func NewTokenManager(cfg *config.AuthConfig) (*TokenManager, error) {
+	if cfg == nil || len(cfg.SigningKeys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+
+	keys := make(map[string][]byte, len(cfg.SigningKeys))
+	for _, key := range cfg.SigningKeys {
+		if key.ID == "" {
+			return nil, errors.New("signing keys must have an id")
+		}
+
+		if len(key.Secret) < minKeyLength {
+			return nil, errors.Errorf("signing key %q must be at least %d bytes", key.ID, minKeyLength)
+		}
+
+		if _, ok := keys[key.ID]; ok {
+			return nil, errors.Errorf("signing key %q is listed twice", key.ID)
+		}
+		keys[key.ID] = []byte(key.Secret)
+	}
+
+	expiry := cfg.TokenExpiry
+	if expiry <= 0 {
+		expiry = defaultTokenExpiry
+	}
+
+	refreshExpiry := cfg.RefreshTokenExpiry
+	if refreshExpiry <= 0 {
+		refreshExpiry = defaultRefreshTokenExpiry
+	}
+
+	return &TokenManager{
+		issuer:        cfg.Issuer,
+		expiry:        expiry,
+		refreshExpiry: refreshExpiry,
+		signingKey:    cfg.SigningKeys[0],
+		keys:          keys,
+	}, nil
+}
+
+// Expiry is how long the tokens issued by t are valid for
+func (t *TokenManager) Expiry() time.Duration {
+	return t.expiry
+}
+
+// RefreshExpiry is how long sessions, and the refresh tokens renewing their tokens, last
+func (t *TokenManager) RefreshExpiry() time.Duration {
+	return t.refreshExpiry
+}
+
+// Issue signs a token for the session's user which expires at the returned time
+func (t *TokenManager) Issue(session *app.Session) (string, time.Time, error) {
+	// JWTs hold times in seconds
+	now := time.Now().Truncate(time.Second)
+	expiresAt := now.Add(t.expiry)
+
+	claims := &Claims{
+		SessionID: session.ID,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   session.UserID,
+			Issuer:    t.issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = t.signingKey.ID
+
+	signed, err := token.SignedString([]byte(t.signingKey.Secret))
+	if err != nil {
+		return "", time.Time{}, errors.Wrap(err, "failed to sign token")
+	}
+	return signed, expiresAt, nil
+}
+
+// Parse checks the token's signature, expiry and issuer and returns its claims
+func (t *TokenManager) Parse(token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, t.key)
+	if err != nil {
+		if e, ok := err.(*jwt.ValidationError); ok && e.Errors == jwt.ValidationErrorExpired {
+			return nil, ErrTokenExpired
+		}
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	if claims.Issuer != t.issuer || claims.Subject == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// key finds the key a token was signed with by its kid header, only HMAC tokens are accepted
+// so a token can't pick an algorithm that would have us treat our secret as a public key
+func (t *TokenManager) key(token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, errors.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+
+	id, _ := token.Header["kid"].(string)
+	key, ok := t.keys[id]
+	if !ok {
+		return nil, errors.Errorf("unknown signing key %q", id)
+	}
+	return key, nil
+}
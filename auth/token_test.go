@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/golang-jwt/jwt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldKey = config.SigningKey{ID: "old", Secret: "6b2a1e6f0c3d4e5f8a9b0c1d2e3f4a5b"}
+	newKey = config.SigningKey{ID: "new", Secret: "0f1e2d3c4b5a69788796a5b4c3d2e1f0"}
+)
+
+func TestNewTokenManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.AuthConfig
+		wantErr bool
+	}{
+		{name: "should_accept_keys", cfg: &config.AuthConfig{SigningKeys: []config.SigningKey{newKey, oldKey}}},
+		{name: "should_error_without_config", cfg: nil, wantErr: true},
+		{name: "should_error_without_keys", cfg: &config.AuthConfig{}, wantErr: true},
+		{name: "should_error_for_short_key", cfg: &config.AuthConfig{SigningKeys: []config.SigningKey{{ID: "short", Secret: "secret"}}}, wantErr: true},
+		{name: "should_error_for_key_without_id", cfg: &config.AuthConfig{SigningKeys: []config.SigningKey{{Secret: newKey.Secret}}}, wantErr: true},
+		{name: "should_error_for_duplicate_key", cfg: &config.AuthConfig{SigningKeys: []config.SigningKey{newKey, newKey}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenManager(tt.cfg)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []config.SigningKey
+		wantErr bool
+	}{
+		{name: "should_parse_keys_in_order", value: "new:" + newKey.Secret + ", old:" + oldKey.Secret, want: []config.SigningKey{newKey, oldKey}},
+		{name: "should_keep_colons_in_secret", value: "new:a:b", want: []config.SigningKey{{ID: "new", Secret: "a:b"}}},
+		{name: "should_parse_nothing_from_empty_value", value: ""},
+		{name: "should_error_without_secret", value: "new", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseSigningKeys(tt.value)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+func TestTokenManager(t *testing.T) {
+	session := &app.Session{ID: "session-id", UserID: "user-id"}
+
+	manager, err := NewTokenManager(&config.AuthConfig{Issuer: "test", TokenExpiry: time.Minute, SigningKeys: []config.SigningKey{newKey, oldKey}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	oldManager, err := NewTokenManager(&config.AuthConfig{Issuer: "test", SigningKeys: []config.SigningKey{oldKey}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	otherManager, err := NewTokenManager(&config.AuthConfig{Issuer: "test", SigningKeys: []config.SigningKey{{ID: newKey.ID, Secret: oldKey.Secret}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	otherIssuer, err := NewTokenManager(&config.AuthConfig{Issuer: "other", SigningKeys: []config.SigningKey{newKey}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, expiresAt, err := manager.Issue(session)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+	oldToken, _, err := oldManager.Issue(session)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	forgedToken, _, err := otherManager.Issue(session)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	otherIssuerToken, _, err := otherIssuer.Issue(session)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expired := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		SessionID: session.ID,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   session.UserID,
+			Issuer:    "test",
+			ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+		},
+	})
+	expired.Header["kid"] = newKey.ID
+	expiredToken, err := expired.SignedString([]byte(newKey.Secret))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, &Claims{
+		SessionID:      session.ID,
+		StandardClaims: jwt.StandardClaims{Subject: session.UserID, Issuer: "test"},
+	})
+	unsigned.Header["kid"] = newKey.ID
+	unsignedToken, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "should_accept_issued_token", token: token},
+		{name: "should_accept_token_signed_with_older_key", token: oldToken},
+		{name: "should_reject_token_signed_with_other_secret", token: forgedToken, wantErr: ErrInvalidToken},
+		{name: "should_reject_token_from_other_issuer", token: otherIssuerToken, wantErr: ErrInvalidToken},
+		{name: "should_reject_expired_token", token: expiredToken, wantErr: ErrTokenExpired},
+		{name: "should_reject_unsigned_token", token: unsignedToken, wantErr: ErrInvalidToken},
+		{name: "should_reject_garbage", token: "not.a.token", wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := manager.Parse(tt.token)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, errors.Cause(err))
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, session.ID, claims.SessionID)
+				assert.Equal(t, session.UserID, claims.Subject)
+			}
+		})
+	}
+}
+
+func TestOpaqueToken(t *testing.T) {
+	token, err := NewOpaqueToken()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	other, err := NewOpaqueToken()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, token, 43)
+	assert.NotEqual(t, token, other)
+	assert.Equal(t, HashOpaqueToken(token), HashOpaqueToken(token))
+	assert.NotEqual(t, HashOpaqueToken(token), HashOpaqueToken(other))
+	assert.Len(t, HashOpaqueToken(token), 64)
+}
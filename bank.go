@@ -0,0 +1,47 @@
+package buycoin_challenge2
+
+import (
+	"context"
+	"time"
+)
+
+// BankTypeNUBAN is the type of banks whose account numbers are NUBANs
+const BankTypeNUBAN = "nuban"
+
+// Bank is an entry of the bank directory, synced from a provider's list of banks
+type Bank struct {
+	Code      string    `json:"code" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	LongCode  string    `json:"longcode" gorm:"column:longcode"`
+	Type      string    `json:"type"`
+	Country   string    `json:"country"`
+	Currency  string    `json:"currency"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BankSuggestion is a bank an account number could belong to, Confirmed is set
+// when a provider resolved the account at the bank to AccountName
+type BankSuggestion struct {
+	Bank        *Bank  `json:"bank"`
+	AccountName string `json:"account_name"`
+	Confirmed   bool   `json:"confirmed"`
+}
+
+// BankDirectory lists the banks a provider can resolve accounts at
+type BankDirectory interface {
+	FetchBanks(ctx context.Context) ([]*Bank, error)
+}
+
+type BankRepository interface {
+	// SaveBanks inserts or updates banks and deactivates every bank missing from them
+	SaveBanks(ctx context.Context, banks []*Bank) error
+	// FindBankByCode returns an error caused by ErrUnknownBankCode when no active bank has the code
+	FindBankByCode(ctx context.Context, code string) (*Bank, error)
+	// FindBanks returns the active banks whose names contain search, all of them when search is empty
+	FindBanks(ctx context.Context, search string) ([]*Bank, error)
+	CountBanks(ctx context.Context) (int64, error)
+	DeleteAllBanks() error
+}
@@ -1,7 +1,67 @@
 package buycoin_challenge2
 
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrAccountNotFound is the cause of errors returned by a BankAccountResolver
+	// when the provider reports that the bank account does not exist
+	ErrAccountNotFound = errors.New("bank account not found")
+	// ErrUnknownBankCode is the cause of errors returned by a BankAccountResolver
+	// when the provider doesn't know the bank code
+	ErrUnknownBankCode = errors.New("unknown bank code")
+	// ErrProviderUnavailable is the cause of errors returned by a BankAccountResolver
+	// when the provider could not be reached or failed to handle the request
+	ErrProviderUnavailable = errors.New("bank account provider unavailable")
+)
+
 type BankAccount struct {
 	UserAccountNumber string `json:"user_account_number"`
 	UserBankCode      string `json:"user_bank_code"`
 	UserAccountName   string `json:"user_account_name"`
 }
+
+// ResolvedBankAccount is the account a provider found for a bank code and account number
+type ResolvedBankAccount struct {
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+	BankCode      string `json:"bank_code"`
+	Provider      string `json:"provider"`
+	// Disagreement is what another provider returned when it resolved the account to a different name
+	Disagreement *ResolvedBankAccount `json:"disagreement,omitempty"`
+}
+
+// BankAccountResolver looks up the name on a bank account with an external provider
+type BankAccountResolver interface {
+	ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*ResolvedBankAccount, error)
+}
+
+// CachedBankAccount is a resolved bank account kept in a BankAccountCache until ExpiresAt,
+// NotFound marks accounts the provider reported as missing
+type CachedBankAccount struct {
+	BankCode      string    `json:"bank_code" gorm:"primaryKey"`
+	AccountNumber string    `json:"account_number" gorm:"primaryKey"`
+	AccountName   string    `json:"account_name"`
+	Provider      string    `json:"provider"`
+	NotFound      bool      `json:"not_found"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BankAccountCache stores resolved bank accounts, FindCachedBankAccount returns nil
+// without an error for accounts that were never cached or have expired
+type BankAccountCache interface {
+	FindCachedBankAccount(ctx context.Context, bankCode string, accountNumber string) (*CachedBankAccount, error)
+	SaveCachedBankAccount(ctx context.Context, account *CachedBankAccount) error
+}
+
+// PurgeableBankAccountCache is a BankAccountCache that keeps expired accounts until they are deleted,
+// rather than evicting them as new ones are saved
+type PurgeableBankAccountCache interface {
+	BankAccountCache
+	DeleteExpiredCachedBankAccounts(ctx context.Context, now time.Time) (int64, error)
+}
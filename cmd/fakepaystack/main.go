@@ -0,0 +1,47 @@
+// Command fakepaystack serves the paystack endpoints used by this service from a fixtures file,
+// set paystack.base_url in the config file to its address to develop without reaching paystack
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/danvixent/buycoin-challenge2/providers/paystack/fake"
+)
+
+var (
+	addr            = flag.String("addr", ":8090", "address to listen on")
+	fixturesPath    = flag.String("fixtures_path", "config/paystack_fixtures.yml", "path to fixtures file")
+	apiKey          = flag.String("api_key", "", "secret key requests must carry, any key is accepted when empty")
+	latency         = flag.Duration("latency", 0, "delay added to every response")
+	errorRate       = flag.Float64("error_rate", 0, "fraction of requests, between 0 and 1, to fail with -error_status_code")
+	errorStatusCode = flag.Int("error_status_code", http.StatusServiceUnavailable, "status code of failed requests")
+)
+
+func main() {
+	flag.Parse()
+
+	fixtures, err := fake.LoadFixtures(*fixturesPath)
+	if err != nil {
+		log.Fatalf("failed to load fixtures: %v", err)
+	}
+
+	server := fake.NewServer(fixtures,
+		fake.WithAPIKey(*apiKey),
+		fake.WithLatency(*latency),
+		fake.WithErrorRate(*errorRate, *errorStatusCode),
+	)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	log.Printf("serving fake paystack at %s with %d banks and %d accounts", *addr, len(fixtures.Banks), len(fixtures.Accounts))
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatalf("unable to listen: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -10,12 +11,19 @@ import (
 	"syscall"
 	"time"
 
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/auth"
 	"github.com/danvixent/buycoin-challenge2/config"
 	"github.com/danvixent/buycoin-challenge2/datastore/postgres"
 	"github.com/danvixent/buycoin-challenge2/graphql"
 	"github.com/danvixent/buycoin-challenge2/handlers/account"
+	"github.com/danvixent/buycoin-challenge2/handlers/bank"
+	"github.com/danvixent/buycoin-challenge2/handlers/health"
+	"github.com/danvixent/buycoin-challenge2/password"
+	"github.com/danvixent/buycoin-challenge2/providers/flutterwave"
 	"github.com/danvixent/buycoin-challenge2/providers/paystack"
-	"gopkg.in/yaml.v2"
+	"github.com/danvixent/buycoin-challenge2/providers/resolver"
+	"github.com/sirupsen/logrus"
 )
 
 var configPath *string
@@ -29,26 +37,94 @@ func init() {
 }
 
 func main() {
-	file, err := os.Open(*configPath)
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatalf("unable to open config file: %v", err)
+		log.Fatalf("failed to load config: %v", err)
 	}
 
-	cfg := &config.BaseConfig{}
-	err = yaml.NewDecoder(file).Decode(cfg)
+	postgresClient := postgres.New(context.Background(), cfg.Postgres)
+	userRepo := postgres.NewUserRepository(postgresClient)
+	verificationRepo := postgres.NewVerificationRepository(postgresClient)
+	bankRepo := postgres.NewBankRepository(postgresClient)
+	identityRepo := postgres.NewIdentityRepository(postgresClient)
+	sessionRepo := postgres.NewSessionRepository(postgresClient)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(postgresClient)
+
+	// names are matched without variants when name_matcher isn't configured
+	var nameVariants *account.NameVariants
+	if cfg.NameMatcher != nil {
+		nameVariants, err = account.LoadNameVariants(cfg.NameMatcher.VariantsPath)
+		if err != nil {
+			log.Fatalf("failed to load name variants: %v", err)
+		}
+		go nameVariants.Watch(context.Background(), cfg.NameMatcher.VariantsReloadInterval, logrus.WithField("component", "name_variants"))
+	}
+
+	nameMatcher, err := account.NewNameMatcher(cfg.NameMatcher, nameVariants)
 	if err != nil {
-		log.Fatalf("failed to decode config file: %v", err)
+		log.Fatalf("failed to create name matcher: %v", err)
 	}
 
-	postgresClient := postgres.New(context.Background(), cfg.Postgres)
-	userRepo := postgres.NewUserRepository(postgresClient)
-	paystackClient := paystack.NewAPIClient(cfg.PaystackAPIKey)
+	paystackClient := paystack.NewAPIClient(cfg.PaystackAPIKey, paystack.ConfigOptions(cfg.Paystack)...)
+	accountResolver, err := newAccountResolver(cfg, paystackClient, nameMatcher)
+	if err != nil {
+		log.Fatalf("failed to create account resolver: %v", err)
+	}
+
+	breakers := map[string]*resolver.CircuitBreaker{}
+	if cfg.AccountResolver != nil && cfg.AccountResolver.CircuitBreaker != nil {
+		breaker := resolver.NewCircuitBreaker(accountResolver, cfg.AccountResolver.CircuitBreaker)
+		breakers["account_resolver"] = breaker
+		accountResolver = breaker
+	}
+
+	caches := map[string]*resolver.CachingResolver{}
+	if cfg.AccountResolver != nil && cfg.AccountResolver.Cache != nil {
+		cache, err := newBankAccountCache(cfg.AccountResolver.Cache, postgresClient)
+		if err != nil {
+			log.Fatalf("failed to create bank account cache: %v", err)
+		}
+
+		// the cache sits outside the circuit breaker so cached accounts resolve while it's open
+		cachingResolver := resolver.NewCachingResolver(accountResolver, cache, cfg.AccountResolver.Cache, logrus.WithField("component", "account_cache"))
+		caches["account_resolver"] = cachingResolver
+		accountResolver = cachingResolver
+	}
+
+	bankHandler := bank.NewHandler(bankRepo, paystackClient, accountResolver, cfg.BankDirectory)
+	var syncInterval time.Duration
+	if cfg.BankDirectory != nil {
+		syncInterval = cfg.BankDirectory.SyncInterval
+	}
+	go bankHandler.Sync(context.Background(), syncInterval, logrus.WithField("component", "bank_directory"))
+
+	// keys from the environment come first, so one of them signs new tokens
+	envKeys, err := auth.ParseSigningKeys(os.Getenv(auth.SigningKeysEnv))
+	if err != nil {
+		log.Fatalf("failed to read %s: %v", auth.SigningKeysEnv, err)
+	}
+	if cfg.Auth == nil {
+		cfg.Auth = &config.AuthConfig{}
+	}
+	cfg.Auth.SigningKeys = append(envKeys, cfg.Auth.SigningKeys...)
+
+	tokenManager, err := auth.NewTokenManager(cfg.Auth)
+	if err != nil {
+		log.Fatalf("failed to create token manager: %v", err)
+	}
 
-	accountHandler := account.NewHandler(userRepo, paystackClient)
-	graphqlHandler := graphql.NewHandler(accountHandler)
+	hasher, err := password.NewHasher(cfg.Password)
+	if err != nil {
+		log.Fatalf("failed to create password hasher: %v", err)
+	}
+
+	accountHandler := account.NewHandler(userRepo, verificationRepo, identityRepo, bankRepo, sessionRepo, refreshTokenRepo, accountResolver, paystackClient, nameMatcher, tokenManager, hasher)
+	graphqlHandler := graphql.NewHandler(accountHandler, bankHandler)
+	healthHandler := health.NewHandler(breakers, caches)
 
 	mux := http.NewServeMux()
 	graphqlHandler.SetupRoutes(mux)
+	healthHandler.SetupRoutes(mux)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -71,7 +147,7 @@ func main() {
 
 	// Wait for interrupt signal to gracefully shutdown the server with
 	// a timeout of 5 seconds.
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscanll.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall. SIGKILL but can"t be catch, so no need to add it
@@ -90,3 +166,50 @@ func main() {
 	}
 	log.Print("server exiting")
 }
+
+// newAccountResolver chains the providers listed under account_resolver in the config,
+// paystack alone is used when none are listed
+func newAccountResolver(cfg *config.BaseConfig, paystackClient *paystack.APIClient, nameMatcher account.NameMatcher) (app.BankAccountResolver, error) {
+	available := map[string]app.BankAccountResolver{
+		paystack.ProviderName: paystackClient,
+	}
+	if cfg.Flutterwave != nil {
+		available[flutterwave.ProviderName] = flutterwave.NewAPIClient(cfg.Flutterwave)
+	}
+
+	if cfg.AccountResolver == nil || len(cfg.AccountResolver.Providers) == 0 {
+		return available[paystack.ProviderName], nil
+	}
+
+	var providers []resolver.Provider
+	for _, name := range cfg.AccountResolver.Providers {
+		r, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("bank account provider %q is unknown or not configured", name)
+		}
+		providers = append(providers, resolver.Provider{Name: name, Resolver: r})
+	}
+
+	composite := resolver.NewComposite(providers, logrus.WithField("component", "account_resolver"))
+	if cfg.AccountResolver.Consensus {
+		composite.WithConsensus(func(a, b string) bool {
+			return nameMatcher.Match(a, b).Decision == app.DecisionApproved
+		})
+	}
+	return composite, nil
+}
+
+// newBankAccountCache creates the cache backend set under account_resolver.cache, memory is used when none is set
+func newBankAccountCache(cfg *config.AccountCacheConfig, postgresClient *postgres.Client) (app.BankAccountCache, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return resolver.NewMemoryCache(cfg.Size), nil
+	case "postgres":
+		// expired rows aren't evicted like in memory, so they're deleted in the background
+		cache := postgres.NewBankAccountCache(postgresClient)
+		go resolver.PurgeExpired(context.Background(), cache, cfg.PurgeInterval, logrus.WithField("component", "account_cache"))
+		return cache, nil
+	default:
+		return nil, fmt.Errorf("bank account cache backend %q is unknown", cfg.Backend)
+	}
+}
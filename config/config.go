@@ -1,8 +1,17 @@
 package config
 
+import "time"
+
 type BaseConfig struct {
-	PaystackAPIKey string          `yaml:"paystack_api_key"`
-	Postgres       *PostgresConfig `yaml:"postgres"`
+	PaystackAPIKey  string                 `yaml:"paystack_api_key"`
+	Paystack        *PaystackConfig        `yaml:"paystack"`
+	Postgres        *PostgresConfig        `yaml:"postgres"`
+	NameMatcher     *NameMatcherConfig     `yaml:"name_matcher"`
+	Flutterwave     *FlutterwaveConfig     `yaml:"flutterwave"`
+	AccountResolver *AccountResolverConfig `yaml:"account_resolver"`
+	BankDirectory   *BankDirectoryConfig   `yaml:"bank_directory"`
+	Auth            *AuthConfig            `yaml:"auth"`
+	Password        *PasswordConfig        `yaml:"password"`
 }
 
 type PostgresConfig struct {
@@ -13,3 +22,126 @@ type PostgresConfig struct {
 	Password string `yaml:"password"`
 	MaxConn  int    `yaml:"max_conn"`
 }
+
+// NameMatcherConfig selects how submitted account names are compared with resolved ones.
+// Strategy is one of levenshtein, damerau_levenshtein, jaro_winkler, token_set, token or phonetic, and
+// Threshold is the minimum score between 0 and 1 needed to accept a match, and scores from
+// ReviewThreshold up to Threshold are queued for a manual review instead of being rejected.
+// Titles lists honorifics removed from the start of both names before they are compared, replacing
+// the built in list when set.
+// VariantsPath points to a YAML or CSV dictionary of name variants like TUNDE and BABATUNDE,
+// which is reloaded every VariantsReloadInterval when the file changes
+type NameMatcherConfig struct {
+	Strategy               string        `yaml:"strategy"`
+	Threshold              float64       `yaml:"threshold"`
+	ReviewThreshold        float64       `yaml:"review_threshold"`
+	Titles                 []string      `yaml:"titles"`
+	VariantsPath           string        `yaml:"variants_path"`
+	VariantsReloadInterval time.Duration `yaml:"variants_reload_interval"`
+}
+
+// PaystackConfig configures the paystack account resolution provider, BaseURL defaults to
+// https://api.paystack.co and a zero Timeout disables the timeout of every request attempt
+type PaystackConfig struct {
+	BaseURL   string        `yaml:"base_url"`
+	Timeout   time.Duration `yaml:"timeout"`
+	UserAgent string        `yaml:"user_agent"`
+	Retry     *RetryConfig  `yaml:"retry"`
+}
+
+// RetryConfig controls how often and how fast failed provider requests are retried.
+// Backoff starts at InitialBackoff and doubles after every attempt up to MaxBackoff,
+// and is then shifted randomly by up to Jitter (a fraction between 0 and 1) of itself
+type RetryConfig struct {
+	MaxAttempts          int           `yaml:"max_attempts"`
+	InitialBackoff       time.Duration `yaml:"initial_backoff"`
+	MaxBackoff           time.Duration `yaml:"max_backoff"`
+	Jitter               float64       `yaml:"jitter"`
+	RetryableStatusCodes []int         `yaml:"retryable_status_codes"`
+}
+
+// FlutterwaveConfig configures the flutterwave account resolution provider,
+// BaseURL defaults to https://api.flutterwave.com and a zero Timeout disables the timeout
+type FlutterwaveConfig struct {
+	SecretKey string        `yaml:"secret_key"`
+	BaseURL   string        `yaml:"base_url"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// AccountResolverConfig lists the providers used to resolve bank accounts in order of priority,
+// the next provider is tried when one is unavailable. With Consensus set, the first two
+// providers are asked at once and accounts they disagree on are queued for a manual review
+type AccountResolverConfig struct {
+	Providers      []string              `yaml:"providers"`
+	Consensus      bool                  `yaml:"consensus"`
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit_breaker"`
+	Cache          *AccountCacheConfig   `yaml:"cache"`
+}
+
+// CircuitBreakerConfig opens the circuit around account resolution after FailureThreshold
+// consecutive provider failures, keeps it open for OpenTimeout and then lets
+// HalfOpenMaxRequests trial requests through to decide whether to close it again
+type CircuitBreakerConfig struct {
+	FailureThreshold    int           `yaml:"failure_threshold"`
+	OpenTimeout         time.Duration `yaml:"open_timeout"`
+	HalfOpenMaxRequests int           `yaml:"half_open_max_requests"`
+}
+
+// AccountCacheConfig caches resolved bank accounts for TTL and accounts providers could not find
+// for NegativeTTL, a zero NegativeTTL disables caching missing accounts. Backend is memory, which
+// keeps up to Size of the most recently used accounts, or postgres, which deletes expired accounts
+// every PurgeInterval
+type AccountCacheConfig struct {
+	Backend       string        `yaml:"backend"`
+	Size          int           `yaml:"size"`
+	TTL           time.Duration `yaml:"ttl"`
+	NegativeTTL   time.Duration `yaml:"negative_ttl"`
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+// BankDirectoryConfig controls how often the bank directory is synced from paystack,
+// a zero SyncInterval only syncs it on startup. SuggestionConcurrency bounds how many
+// banks are asked at once to confirm the banks suggested for an account number
+type BankDirectoryConfig struct {
+	SyncInterval          time.Duration `yaml:"sync_interval"`
+	SuggestionConcurrency int           `yaml:"suggestion_concurrency"`
+}
+
+// AuthConfig configures the tokens issued on login, which expire after TokenExpiry. Tokens are signed
+// with the first of SigningKeys and the rest are only used to check tokens, so a new key can be put
+// first without invalidating tokens signed with the old one. Sessions, and the refresh tokens
+// used to renew their tokens, expire RefreshTokenExpiry after login
+type AuthConfig struct {
+	Issuer             string        `yaml:"issuer"`
+	TokenExpiry        time.Duration `yaml:"token_expiry"`
+	RefreshTokenExpiry time.Duration `yaml:"refresh_token_expiry"`
+	SigningKeys        []SigningKey  `yaml:"signing_keys"`
+}
+
+// SigningKey is an HMAC SHA-256 secret, ID is set as the kid header of the tokens it signs
+type SigningKey struct {
+	ID     string `yaml:"id"`
+	Secret string `yaml:"secret"`
+}
+
+// PasswordConfig selects the algorithm new password hashes are created with, scrypt or argon2id, and
+// its cost. Stored hashes made with another algorithm or a lower cost are rehashed when their users log in
+type PasswordConfig struct {
+	Algorithm string          `yaml:"algorithm"`
+	Scrypt    *ScryptConfig   `yaml:"scrypt"`
+	Argon2id  *Argon2idConfig `yaml:"argon2id"`
+}
+
+// ScryptConfig holds the scrypt cost parameters, N must be a power of two and r*p below 2^30
+type ScryptConfig struct {
+	N int `yaml:"n"`
+	R int `yaml:"r"`
+	P int `yaml:"p"`
+}
+
+// Argon2idConfig holds the argon2id cost parameters, Memory is in KiB and must be at least 8 KiB per thread
+type Argon2idConfig struct {
+	Memory  uint32 `yaml:"memory"`
+	Time    uint32 `yaml:"time"`
+	Threads uint8  `yaml:"threads"`
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Load decodes the config file at path. Relative file paths in it, like name_matcher.variants_path,
+// are resolved from the config file's directory rather than the working directory
+func Load(path string) (*BaseConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open config file")
+	}
+	defer file.Close()
+
+	cfg := &BaseConfig{}
+	err = yaml.NewDecoder(file).Decode(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode config file")
+	}
+
+	if cfg.NameMatcher != nil {
+		cfg.NameMatcher.VariantsPath = resolvePath(filepath.Dir(path), cfg.NameMatcher.VariantsPath)
+	}
+	return cfg, nil
+}
+
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
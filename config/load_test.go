@@ -0,0 +1,56 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name             string
+		content          string
+		wantVariantsPath string
+	}{
+		{name: "should_resolve_relative_path_from_config_dir", content: "name_matcher:\n  variants_path: name_variants.yml\n", wantVariantsPath: filepath.Join(dir, "name_variants.yml")},
+		{name: "should_keep_absolute_path", content: "name_matcher:\n  variants_path: /etc/name_variants.yml\n", wantVariantsPath: "/etc/name_variants.yml"},
+		{name: "should_keep_empty_path", content: "name_matcher:\n  strategy: token\n", wantVariantsPath: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.yml")
+			err := ioutil.WriteFile(path, []byte(tt.content), 0600)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			cfg, err := Load(path)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantVariantsPath, cfg.NameMatcher.VariantsPath)
+			}
+		})
+	}
+
+	t.Run("should_load_without_name_matcher", func(t *testing.T) {
+		path := filepath.Join(dir, "config.yml")
+		err := ioutil.WriteFile(path, []byte("paystack_api_key: key\n"), 0600)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		cfg, err := Load(path)
+		if assert.NoError(t, err) {
+			assert.Nil(t, cfg.NameMatcher)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BankAccountCache struct {
+	client *Client
+}
+
+func NewBankAccountCache(client *Client) app.PurgeableBankAccountCache {
+	return &BankAccountCache{client: client}
+}
+
+func (b *BankAccountCache) FindCachedBankAccount(ctx context.Context, bankCode string, accountNumber string) (*app.CachedBankAccount, error) {
+	account := &app.CachedBankAccount{}
+	err := b.client.db.
+		Where("bank_code = ?", bankCode).
+		Where("account_number = ?", accountNumber).
+		Where("expires_at > ?", time.Now()).
+		First(account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (b *BankAccountCache) SaveCachedBankAccount(ctx context.Context, account *app.CachedBankAccount) error {
+	return b.client.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(account).Error
+}
+
+// DeleteExpiredCachedBankAccounts deletes the accounts that expired by now and returns how many there were
+func (b *BankAccountCache) DeleteExpiredCachedBankAccounts(ctx context.Context, now time.Time) (int64, error) {
+	result := b.client.db.Where("expires_at <= ?", now).Delete(&app.CachedBankAccount{})
+	return result.RowsAffected, result.Error
+}
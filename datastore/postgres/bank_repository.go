@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	pkgerrors "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BankRepository struct {
+	client *Client
+}
+
+func NewBankRepository(client *Client) app.BankRepository {
+	return &BankRepository{client: client}
+}
+
+func (b *BankRepository) SaveBanks(ctx context.Context, banks []*app.Bank) error {
+	if len(banks) == 0 {
+		return nil
+	}
+
+	codes := make([]string, 0, len(banks))
+	for _, bank := range banks {
+		bank.CreatedAt = time.Now()
+		bank.UpdatedAt = time.Now()
+		codes = append(codes, bank.Code)
+	}
+
+	return b.client.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "slug", "longcode", "type", "country", "currency", "active", "updated_at"}),
+		}).Create(banks).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&app.Bank{}).
+			Where("code NOT IN ?", codes).
+			Updates(map[string]interface{}{"active": false, "updated_at": time.Now()}).Error
+	})
+}
+
+func (b *BankRepository) FindBankByCode(ctx context.Context, code string) (*app.Bank, error) {
+	bank := &app.Bank{}
+	err := b.client.db.
+		Where("code = ?", code).
+		Where("active").
+		First(bank).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, pkgerrors.Wrapf(app.ErrUnknownBankCode, "bank code %s", code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bank, nil
+}
+
+func (b *BankRepository) FindBanks(ctx context.Context, search string) ([]*app.Bank, error) {
+	var banks []*app.Bank
+	query := b.client.db.Where("active")
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	err := query.Order("name").Find(&banks).Error
+	if err != nil {
+		return nil, err
+	}
+	return banks, nil
+}
+
+func (b *BankRepository) CountBanks(ctx context.Context) (int64, error) {
+	var count int64
+	err := b.client.db.Model(&app.Bank{}).Where("active").Count(&count).Error
+	return count, err
+}
+
+func (b *BankRepository) DeleteAllBanks() error {
+	return b.client.db.Where("code IS NOT NULL").Delete(&app.Bank{}).Error
+}
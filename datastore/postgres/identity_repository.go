@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct {
+	client *Client
+}
+
+func NewIdentityRepository(client *Client) app.IdentityRepository {
+	return &IdentityRepository{client: client}
+}
+
+func (i *IdentityRepository) SaveUserIdentity(ctx context.Context, identity *app.UserIdentity) error {
+	identity.CreatedAt = time.Now()
+	identity.UpdatedAt = time.Now()
+	return i.client.db.Create(identity).Error
+}
+
+func (i *IdentityRepository) UpdateUserIdentity(ctx context.Context, identity *app.UserIdentity) error {
+	identity.UpdatedAt = time.Now()
+	return i.client.db.Session(&gorm.Session{FullSaveAssociations: true}).Save(identity).Error
+}
+
+func (i *IdentityRepository) FindUserIdentityByID(ctx context.Context, id string) (*app.UserIdentity, error) {
+	identity := &app.UserIdentity{}
+	err := i.client.db.
+		Preload("User").
+		Where("id = ?", id).
+		First(identity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+func (i *IdentityRepository) FindUserIdentitiesByUserID(ctx context.Context, userID string) ([]*app.UserIdentity, error) {
+	var identities []*app.UserIdentity
+	err := i.client.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (i *IdentityRepository) FindUserIdentitiesByStatus(ctx context.Context, status string) ([]*app.UserIdentity, error) {
+	var identities []*app.UserIdentity
+	err := i.client.db.
+		Preload("User").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (i *IdentityRepository) HasVerifiedUserIdentity(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	err := i.client.db.
+		Model(&app.UserIdentity{}).
+		Where("user_id = ?", userID).
+		Where("status = ?", app.IdentityStatusVerified).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (i *IdentityRepository) DeleteAllUserIdentities() error {
+	return i.client.db.Model(&app.UserIdentity{}).Where("id IS NOT NULL").Delete("").Error
+}
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS verification_attempts (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid REFERENCES users(id) NOT NULL ,
+    bank_code VARCHAR (20) NOT NULL ,
+    account_number VARCHAR (20) NOT NULL ,
+    submitted_name VARCHAR (300) NOT NULL ,
+    resolved_name VARCHAR (300) NOT NULL ,
+    strategy VARCHAR (50) NOT NULL ,
+    score DOUBLE PRECISION NOT NULL ,
+    distance INT NOT NULL ,
+    decision VARCHAR (50) NOT NULL ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS verification_attempts_user_id_idx ON verification_attempts(user_id, created_at);
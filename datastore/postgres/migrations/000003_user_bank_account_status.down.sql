@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_bank_accounts_status_idx;
+ALTER TABLE user_bank_accounts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,3 @@
+ALTER TABLE user_bank_accounts ADD COLUMN IF NOT EXISTS status VARCHAR (20) NOT NULL DEFAULT 'verified';
+
+CREATE INDEX IF NOT EXISTS user_bank_accounts_status_idx ON user_bank_accounts(status);
@@ -0,0 +1 @@
+ALTER TABLE verification_attempts DROP COLUMN IF EXISTS provider;
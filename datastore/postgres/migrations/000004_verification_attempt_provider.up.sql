@@ -0,0 +1 @@
+ALTER TABLE verification_attempts ADD COLUMN IF NOT EXISTS provider VARCHAR (50) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cached_bank_accounts;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS cached_bank_accounts (
+    bank_code VARCHAR (20) NOT NULL ,
+    account_number VARCHAR (20) NOT NULL ,
+    account_name VARCHAR (300) NOT NULL DEFAULT '' ,
+    provider VARCHAR (50) NOT NULL DEFAULT '' ,
+    not_found BOOLEAN NOT NULL DEFAULT FALSE ,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP ,
+    PRIMARY KEY (bank_code, account_number)
+);
+
+CREATE INDEX IF NOT EXISTS cached_bank_accounts_expires_at_idx ON cached_bank_accounts(expires_at);
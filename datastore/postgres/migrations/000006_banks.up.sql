@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS banks (
+    code VARCHAR (20) PRIMARY KEY ,
+    name VARCHAR (300) NOT NULL ,
+    slug VARCHAR (300) NOT NULL DEFAULT '' ,
+    longcode VARCHAR (20) NOT NULL DEFAULT '' ,
+    type VARCHAR (50) NOT NULL DEFAULT '' ,
+    country VARCHAR (100) NOT NULL DEFAULT '' ,
+    currency VARCHAR (10) NOT NULL DEFAULT '' ,
+    active BOOLEAN NOT NULL DEFAULT TRUE ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS banks_name_idx ON banks(name);
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS date_of_birth DATE;
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid REFERENCES users(id) NOT NULL ,
+    masked_bvn VARCHAR (20) NOT NULL ,
+    first_name VARCHAR (100) NOT NULL DEFAULT '' ,
+    middle_name VARCHAR (100) NOT NULL DEFAULT '' ,
+    last_name VARCHAR (100) NOT NULL DEFAULT '' ,
+    date_of_birth DATE ,
+    provider VARCHAR (50) NOT NULL DEFAULT '' ,
+    strategy VARCHAR (50) NOT NULL DEFAULT '' ,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0 ,
+    decision VARCHAR (50) NOT NULL ,
+    status VARCHAR (20) NOT NULL ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities(user_id, status);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid REFERENCES users(id) NOT NULL ,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL ,
+    revoked_at TIMESTAMP WITH TIME ZONE ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions(user_id);
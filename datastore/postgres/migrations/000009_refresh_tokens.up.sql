@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id uuid REFERENCES sessions(id) NOT NULL ,
+    token_hash VARCHAR (64) UNIQUE NOT NULL ,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL ,
+    used_at TIMESTAMP WITH TIME ZONE ,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_id_idx ON refresh_tokens(session_id);
@@ -0,0 +1,14 @@
+-- only hashes with the parameters the JSON format assumed, N=16384, r=8, p=1, can be converted back,
+-- so the migration stops before touching anything when another algorithm or parameters were used
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE left(password, 22) <> '$scrypt$ln=14,r=8,p=1$') THEN
+        RAISE EXCEPTION 'password hashes made with another algorithm or parameters than scrypt N=16384, r=8, p=1 cannot be converted back';
+    END IF;
+END
+$$;
+
+ALTER TABLE users ALTER COLUMN password TYPE jsonb USING jsonb_build_object(
+    'Hash', rpad(split_part(password, '$', 5), (length(split_part(password, '$', 5)) + 3) / 4 * 4, '='),
+    'Salt', rpad(split_part(password, '$', 4), (length(split_part(password, '$', 4)) + 3) / 4 * 4, '=')
+);
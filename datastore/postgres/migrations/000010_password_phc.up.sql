@@ -0,0 +1,3 @@
+-- hashes were stored as {"Hash": "<base64>", "Salt": "<base64>"} and all used scrypt with N=16384, r=8, p=1
+ALTER TABLE users ALTER COLUMN password TYPE TEXT USING
+    '$scrypt$ln=14,r=8,p=1$' || rtrim(password->>'Salt', '=') || '$' || rtrim(password->>'Hash', '=');
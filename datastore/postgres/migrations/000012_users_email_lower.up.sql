@@ -0,0 +1,2 @@
+-- emails are compared case insensitively, so Dan@gmail.com and dan@gmail.com are one account
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_idx ON users (lower(email));
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+)
+
+type RefreshTokenRepository struct {
+	client *Client
+}
+
+func NewRefreshTokenRepository(client *Client) app.RefreshTokenRepository {
+	return &RefreshTokenRepository{client: client}
+}
+
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *app.RefreshToken) error {
+	token.CreatedAt = time.Now()
+	return r.client.db.Create(token).Error
+}
+
+func (r *RefreshTokenRepository) FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*app.RefreshToken, error) {
+	token := &app.RefreshToken{}
+	err := r.client.db.Where("token_hash = ?", tokenHash).First(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// MarkRefreshTokenUsed only updates unused tokens, so of two requests racing to use
+// the same token only one sees true
+func (r *RefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	result := r.client.db.Model(&app.RefreshToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RefreshTokenRepository) DeleteAllRefreshTokens() error {
+	return r.client.db.Model(&app.RefreshToken{}).Where("id IS NOT NULL").Delete("").Error
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+)
+
+type SessionRepository struct {
+	client *Client
+}
+
+func NewSessionRepository(client *Client) app.SessionRepository {
+	return &SessionRepository{client: client}
+}
+
+func (s *SessionRepository) CreateSession(ctx context.Context, session *app.Session) error {
+	session.CreatedAt = time.Now()
+	return s.client.db.Create(session).Error
+}
+
+func (s *SessionRepository) FindSessionByID(ctx context.Context, id string) (*app.Session, error) {
+	session := &app.Session{}
+	err := s.client.db.Where("id = ?", id).First(session).Error
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionRepository) RevokeSession(ctx context.Context, id string, revokedAt time.Time) error {
+	return s.client.db.Model(&app.Session{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", revokedAt).Error
+}
+
+func (s *SessionRepository) RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time) error {
+	return s.client.db.Model(&app.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID).Update("revoked_at", revokedAt).Error
+}
+
+func (s *SessionRepository) DeleteAllSessions() error {
+	return s.client.db.Model(&app.Session{}).Where("id IS NOT NULL").Delete("").Error
+}
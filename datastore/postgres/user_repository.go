@@ -30,6 +30,15 @@ func (u *UserRepository) FindUserByID(ctx context.Context, id string) (*app.User
 	return user, nil
 }
 
+func (u *UserRepository) FindUserByEmail(ctx context.Context, email string) (*app.User, error) {
+	user := &app.User{}
+	err := u.client.db.Where("lower(email) = lower(?)", email).First(user).Error
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (u *UserRepository) CreateUser(ctx context.Context, user *app.User) error {
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
@@ -43,6 +52,7 @@ func (u *UserRepository) SaveUserBankAccount(ctx context.Context, account *app.U
 		Where("user_id = ?", account.UserID).
 		Where("bank_account ->> 'user_bank_code' = ?", account.BankAccount.UserBankCode).
 		Where("bank_account ->> 'user_account_number' = ?", account.BankAccount.UserAccountNumber).
+		Where("status <> ?", app.BankAccountStatusRejected).
 		Count(&count).Error
 	if err != nil {
 		return err
@@ -57,12 +67,19 @@ func (u *UserRepository) SaveUserBankAccount(ctx context.Context, account *app.U
 	return u.client.db.Session(&gorm.Session{FullSaveAssociations: true}).Save(account).Error
 }
 
+func (u *UserRepository) UpdateUserBankAccount(ctx context.Context, account *app.UserBankAccount) error {
+	account.UpdatedAt = time.Now()
+	return u.client.db.Session(&gorm.Session{FullSaveAssociations: true}).Save(account).Error
+}
+
+// FindUserBankAccount finds a verified bank account, accounts pending review or rejected are ignored
 func (u *UserRepository) FindUserBankAccount(ctx context.Context, bankCode string, accountNumber string) (*app.UserBankAccount, error) {
 	account := &app.UserBankAccount{}
 	err := u.client.db.
 		Model(account).
 		Where("bank_account ->> 'user_bank_code' = ?", bankCode).
 		Where("bank_account ->> 'user_account_number' = ?", accountNumber).
+		Where("status = ?", app.BankAccountStatusVerified).
 		First(account).Error
 	if err != nil {
 		return nil, err
@@ -71,6 +88,47 @@ func (u *UserRepository) FindUserBankAccount(ctx context.Context, bankCode strin
 	return account, nil
 }
 
+func (u *UserRepository) FindUserBankAccountByID(ctx context.Context, id string) (*app.UserBankAccount, error) {
+	account := &app.UserBankAccount{}
+	err := u.client.db.
+		Preload("User").
+		Where("id = ?", id).
+		First(account).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (u *UserRepository) FindUserBankAccountsByStatus(ctx context.Context, status string) ([]*app.UserBankAccount, error) {
+	var accounts []*app.UserBankAccount
+	err := u.client.db.
+		Preload("User").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (u *UserRepository) HasVerifiedUserBankAccount(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	err := u.client.db.
+		Model(&app.UserBankAccount{}).
+		Where("user_id = ?", userID).
+		Where("status = ?", app.BankAccountStatusVerified).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func (u *UserRepository) DeleteAllUsers() error {
 	return u.client.db.Model(&app.User{}).Where("id IS NOT NULL").Delete("").Error
 }
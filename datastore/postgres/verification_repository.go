@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+)
+
+type VerificationRepository struct {
+	client *Client
+}
+
+func NewVerificationRepository(client *Client) app.VerificationRepository {
+	return &VerificationRepository{client: client}
+}
+
+func (v *VerificationRepository) SaveVerificationAttempt(ctx context.Context, attempt *app.VerificationAttempt) error {
+	attempt.CreatedAt = time.Now()
+	return v.client.db.Create(attempt).Error
+}
+
+func (v *VerificationRepository) FindVerificationAttemptsByUserID(ctx context.Context, userID string) ([]*app.VerificationAttempt, error) {
+	var attempts []*app.VerificationAttempt
+	err := v.client.db.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (v *VerificationRepository) DeleteAllVerificationAttempts() error {
+	return v.client.db.Model(&app.VerificationAttempt{}).Where("id IS NOT NULL").Delete("").Error
+}
@@ -5,12 +5,14 @@ go 1.16
 require (
 	github.com/99designs/gqlgen v0.13.0
 	github.com/agnivade/levenshtein v1.1.0
+	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/pkg/errors v0.8.1
 	github.com/sirupsen/logrus v1.4.2
-	github.com/stretchr/testify v1.7.0 // indirect
+	github.com/stretchr/testify v1.7.0
 	github.com/vektah/gqlparser v1.1.2
-	github.com/vektah/gqlparser/v2 v2.1.0 // indirect
+	github.com/vektah/gqlparser/v2 v2.1.0
 	golang.org/x/crypto v0.0.0-20210920023735-84f357641f63
+	golang.org/x/text v0.3.7
 	gopkg.in/yaml.v2 v2.2.4
 	gorm.io/driver/postgres v1.1.1
 	gorm.io/gorm v1.21.15
@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/danvixent/buycoin-challenge2/auth"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUnauthenticated = errors.New("authentication required")
+	ErrForbidden       = errors.New("you can only access your own account")
+	ErrAdminRequired   = errors.New("admin access required")
+)
+
+// roleAdmin is the @auth role of operations only admins may call
+const roleAdmin = "admin"
+
+// authDirective implements @auth, which only lets authenticated users through. With owner set
+// to the name of an ID argument, the argument must also be the authenticated user's ID unless
+// they are an admin, and with role set to admin the authenticated user must be an admin. Requests
+// whose token was rejected fail with the reason, like token expired, so clients know to refresh
+func authDirective(ctx context.Context, obj interface{}, next graphql.Resolver, owner *string, role *string) (interface{}, error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		if err := auth.TokenErrorFromContext(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrUnauthenticated
+	}
+
+	if role != nil {
+		if *role != roleAdmin {
+			return nil, errors.Errorf("unknown role %q", *role)
+		}
+		if !user.IsAdmin {
+			return nil, ErrAdminRequired
+		}
+	}
+
+	// admins, like support staff explaining a rejection, may act on any user's account
+	if owner != nil && !user.IsAdmin {
+		id, _ := graphql.GetFieldContext(ctx).Args[*owner].(string)
+		if id != user.ID {
+			return nil, ErrForbidden
+		}
+	}
+	return next(ctx)
+}
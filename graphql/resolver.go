@@ -5,7 +5,9 @@ package graphql
 import (
 	"context"
 	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/auth"
 	"github.com/danvixent/buycoin-challenge2/handlers/account"
+	"github.com/danvixent/buycoin-challenge2/handlers/bank"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"time"
@@ -13,6 +15,7 @@ import (
 
 type Resolver struct {
 	accountHandler *account.Handler
+	bankHandler    *bank.Handler
 }
 
 func (r *Resolver) User() UserResolver {
@@ -30,6 +33,14 @@ func (u *userResolver) CreatedAt(ctx context.Context, obj *app.User) (string, er
 	return obj.CreatedAt.Format(time.RFC3339), nil
 }
 
+func (u *userResolver) DateOfBirth(ctx context.Context, obj *app.User) (*string, error) {
+	if obj == nil || obj.DateOfBirth == nil {
+		return nil, nil
+	}
+	dateOfBirth := obj.DateOfBirth.Format("2006-01-02")
+	return &dateOfBirth, nil
+}
+
 func (u *userResolver) UpdatedAt(ctx context.Context, obj *app.User) (string, error) {
 	if obj == nil {
 		return "", nil
@@ -37,6 +48,101 @@ func (u *userResolver) UpdatedAt(ctx context.Context, obj *app.User) (string, er
 	return obj.UpdatedAt.Format(time.RFC3339), nil
 }
 
+func (r *Resolver) UserBankAccount() UserBankAccountResolver {
+	return &userBankAccountResolver{r}
+}
+
+type userBankAccountResolver struct {
+	*Resolver
+}
+
+func (u *userBankAccountResolver) UserAccountNumber(ctx context.Context, obj *app.UserBankAccount) (string, error) {
+	if obj == nil || obj.BankAccount == nil {
+		return "", nil
+	}
+	return obj.BankAccount.UserAccountNumber, nil
+}
+
+func (u *userBankAccountResolver) UserBankCode(ctx context.Context, obj *app.UserBankAccount) (string, error) {
+	if obj == nil || obj.BankAccount == nil {
+		return "", nil
+	}
+	return obj.BankAccount.UserBankCode, nil
+}
+
+func (u *userBankAccountResolver) UserAccountName(ctx context.Context, obj *app.UserBankAccount) (string, error) {
+	if obj == nil || obj.BankAccount == nil {
+		return "", nil
+	}
+	return obj.BankAccount.UserAccountName, nil
+}
+
+func (u *userBankAccountResolver) CreatedAt(ctx context.Context, obj *app.UserBankAccount) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.CreatedAt.Format(time.RFC3339), nil
+}
+
+func (r *Resolver) UserIdentity() UserIdentityResolver {
+	return &userIdentityResolver{r}
+}
+
+type userIdentityResolver struct {
+	*Resolver
+}
+
+func (u *userIdentityResolver) DateOfBirth(ctx context.Context, obj *app.UserIdentity) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.DateOfBirth.Format("2006-01-02"), nil
+}
+
+func (u *userIdentityResolver) CreatedAt(ctx context.Context, obj *app.UserIdentity) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.CreatedAt.Format(time.RFC3339), nil
+}
+
+func (r *Resolver) VerificationAttempt() VerificationAttemptResolver {
+	return &verificationAttemptResolver{r}
+}
+
+type verificationAttemptResolver struct {
+	*Resolver
+}
+
+func (v *verificationAttemptResolver) CreatedAt(ctx context.Context, obj *app.VerificationAttempt) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.CreatedAt.Format(time.RFC3339), nil
+}
+
+func (r *Resolver) Session() SessionResolver {
+	return &sessionResolver{r}
+}
+
+type sessionResolver struct {
+	*Resolver
+}
+
+func (s *sessionResolver) TokenExpiresAt(ctx context.Context, obj *app.Session) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.TokenExpiresAt.Format(time.RFC3339), nil
+}
+
+func (s *sessionResolver) ExpiresAt(ctx context.Context, obj *app.Session) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	return obj.ExpiresAt.Format(time.RFC3339), nil
+}
+
 func (r *Resolver) Mutation() MutationResolver {
 	return &mutationResolver{r}
 }
@@ -65,6 +171,75 @@ func (q *queryResolver) ResolveAccount(ctx context.Context, bankCode string, acc
 	return accountName, nil
 }
 
+func (q *queryResolver) VerificationAttempts(ctx context.Context, userID string) ([]*app.VerificationAttempt, error) {
+	logger := log.WithField("user_id", userID)
+	logger.Info("verification_attempts")
+
+	attempts, err := q.accountHandler.FindVerificationAttempts(ctx, userID, logger)
+	if err != nil {
+		logger.Errorf("find verification attempts failed: %v", err)
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (q *queryResolver) PendingBankAccounts(ctx context.Context) ([]*app.UserBankAccount, error) {
+	logger := log.WithFields(map[string]interface{}{})
+	logger.Info("pending_bank_accounts")
+
+	accounts, err := q.accountHandler.FindPendingBankAccounts(ctx, logger)
+	if err != nil {
+		logger.Errorf("find pending bank accounts failed: %v", err)
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (q *queryResolver) PendingIdentities(ctx context.Context) ([]*app.UserIdentity, error) {
+	logger := log.WithFields(map[string]interface{}{})
+	logger.Info("pending_identities")
+
+	identities, err := q.accountHandler.FindPendingIdentities(ctx, logger)
+	if err != nil {
+		logger.Errorf("find pending identities failed: %v", err)
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (q *queryResolver) Banks(ctx context.Context, search *string) ([]*app.Bank, error) {
+	var name string
+	if search != nil {
+		name = *search
+	}
+
+	logger := log.WithField("search", name)
+	logger.Info("banks")
+
+	banks, err := q.bankHandler.FindBanks(ctx, name, logger)
+	if err != nil {
+		logger.Errorf("find banks failed: %v", err)
+		return nil, err
+	}
+	return banks, nil
+}
+
+func (q *queryResolver) SuggestBanks(ctx context.Context, accountNumber string, confirm *bool) ([]*app.BankSuggestion, error) {
+	logger := log.WithField("account_number", accountNumber)
+	logger.Info("suggest_banks")
+
+	if accountNumber == "" {
+		return nil, errors.New("account number is required")
+	}
+
+	suggestions, err := q.bankHandler.SuggestBanks(ctx, accountNumber, confirm != nil && *confirm, logger)
+	if err != nil {
+		logger.Errorf("suggest banks failed: %v", err)
+		return nil, err
+	}
+	return suggestions, nil
+}
+
 func (r *Resolver) Query() QueryResolver {
 	return &queryResolver{r}
 }
@@ -96,7 +271,7 @@ func (m *mutationResolver) RegisterUser(ctx context.Context, userDetails account
 	return user, nil
 }
 
-func (m mutationResolver) AddBankAccount(ctx context.Context, userID string, input app.BankAccount) (bool, error) {
+func (m mutationResolver) AddBankAccount(ctx context.Context, input app.BankAccount) (bool, error) {
 	if input.UserAccountName == "" {
 		return false, errors.New("user_account_name is required")
 	}
@@ -109,11 +284,137 @@ func (m mutationResolver) AddBankAccount(ctx context.Context, userID string, inp
 		return false, errors.New("user_account_number is required")
 	}
 
-	logger := log.WithFields(map[string]interface{}{})
-	ok, err := m.accountHandler.AddBankAccount(ctx, userID, input, logger)
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return false, ErrUnauthenticated
+	}
+
+	logger := log.WithField("user_id", user.ID)
+	ok, err := m.accountHandler.AddBankAccount(ctx, user.ID, input, logger)
 	if err != nil {
 		logger.Errorf("add bank account failed: %v", err)
 		return false, err
 	}
 	return ok, nil
 }
+
+func (m mutationResolver) ApproveBankAccount(ctx context.Context, id string) (bool, error) {
+	logger := log.WithField("user_bank_account_id", id)
+	ok, err := m.accountHandler.ApproveBankAccount(ctx, id, logger)
+	if err != nil {
+		logger.Errorf("approve bank account failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) RejectBankAccount(ctx context.Context, id string) (bool, error) {
+	logger := log.WithField("user_bank_account_id", id)
+	ok, err := m.accountHandler.RejectBankAccount(ctx, id, logger)
+	if err != nil {
+		logger.Errorf("reject bank account failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) VerifyBvn(ctx context.Context, bvn string, dateOfBirth *string) (bool, error) {
+	if bvn == "" {
+		return false, errors.New("bvn is required")
+	}
+
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return false, ErrUnauthenticated
+	}
+
+	logger := log.WithField("user_id", user.ID)
+	ok, err := m.accountHandler.VerifyBVN(ctx, user.ID, bvn, dateOfBirth, logger)
+	if err != nil {
+		logger.Errorf("verify bvn failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) ApproveIdentity(ctx context.Context, id string) (bool, error) {
+	logger := log.WithField("user_identity_id", id)
+	ok, err := m.accountHandler.ApproveIdentity(ctx, id, logger)
+	if err != nil {
+		logger.Errorf("approve identity failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) RejectIdentity(ctx context.Context, id string) (bool, error) {
+	logger := log.WithField("user_identity_id", id)
+	ok, err := m.accountHandler.RejectIdentity(ctx, id, logger)
+	if err != nil {
+		logger.Errorf("reject identity failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) Login(ctx context.Context, email string, password string) (*app.Session, error) {
+	if email == "" {
+		return nil, errors.New("email is required")
+	}
+
+	if password == "" {
+		return nil, errors.New("password is required")
+	}
+
+	logger := log.WithFields(map[string]interface{}{})
+	session, err := m.accountHandler.Login(ctx, email, password, logger)
+	if err != nil {
+		logger.Errorf("login failed: %v", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+func (m mutationResolver) RefreshSession(ctx context.Context, refreshToken string) (*app.Session, error) {
+	if refreshToken == "" {
+		return nil, errors.New("refresh_token is required")
+	}
+
+	logger := log.WithFields(map[string]interface{}{})
+	session, err := m.accountHandler.RefreshSession(ctx, refreshToken, logger)
+	if err != nil {
+		logger.Errorf("refresh session failed: %v", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+func (m mutationResolver) Logout(ctx context.Context) (bool, error) {
+	session := auth.SessionFromContext(ctx)
+	if session == nil {
+		return false, ErrUnauthenticated
+	}
+
+	logger := log.WithFields(map[string]interface{}{"user_id": session.UserID, "session_id": session.ID})
+	ok, err := m.accountHandler.Logout(ctx, session.ID, logger)
+	if err != nil {
+		logger.Errorf("logout failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
+
+func (m mutationResolver) LogoutAllSessions(ctx context.Context) (bool, error) {
+	user := auth.UserFromContext(ctx)
+	if user == nil {
+		return false, ErrUnauthenticated
+	}
+
+	logger := log.WithField("user_id", user.ID)
+	ok, err := m.accountHandler.LogoutAllSessions(ctx, user.ID, logger)
+	if err != nil {
+		logger.Errorf("logout all sessions failed: %v", err)
+		return false, err
+	}
+	return ok, nil
+}
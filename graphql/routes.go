@@ -2,24 +2,30 @@ package graphql
 
 import (
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/danvixent/buycoin-challenge2/auth"
 	"github.com/danvixent/buycoin-challenge2/handlers/account"
+	"github.com/danvixent/buycoin-challenge2/handlers/bank"
+	log "github.com/sirupsen/logrus"
 	"net/http"
+	"strings"
 )
 
 type Handler struct {
 	accountHandler *account.Handler
+	bankHandler    *bank.Handler
 }
 
 const graphqlEndpoint = "/graphql"
 
-func NewHandler(accountHandler *account.Handler) *Handler {
-	return &Handler{accountHandler: accountHandler}
+func NewHandler(accountHandler *account.Handler, bankHandler *bank.Handler) *Handler {
+	return &Handler{accountHandler: accountHandler, bankHandler: bankHandler}
 }
 
 func (h *Handler) graphqlHandler() http.HandlerFunc {
 	c := Config{
-		Resolvers: &Resolver{accountHandler: h.accountHandler},
+		Resolvers: &Resolver{accountHandler: h.accountHandler, bankHandler: h.bankHandler},
 	}
+	c.Directives.Auth = authDirective
 
 	s := handler.NewDefaultServer(NewExecutableSchema(c))
 
@@ -28,7 +34,36 @@ func (h *Handler) graphqlHandler() http.HandlerFunc {
 
 func (h *Handler) SetupRoutes(mux *http.ServeMux) {
 	graphqlHandlerFunc := h.graphqlHandler()
-	mux.HandleFunc(graphqlEndpoint, handleMethod(http.MethodPost, graphqlHandlerFunc))
+	mux.HandleFunc(graphqlEndpoint, handleMethod(http.MethodPost, h.authenticate(graphqlHandlerFunc)))
+}
+
+// authenticate puts the session a request's bearer token was issued for into its context. Requests
+// without a token, or with one that isn't accepted, go through anonymously and are turned away by
+// the @auth directive where it's needed, so a client with an expired token can still call
+// login and refreshSession
+func (h *Handler) authenticate(handlerFunc http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			handlerFunc(w, r)
+			return
+		}
+
+		session, err := h.accountHandler.Authenticate(r.Context(), token, log.WithField("component", "auth"))
+		if err != nil {
+			handlerFunc(w, r.WithContext(auth.WithTokenError(r.Context(), err)))
+			return
+		}
+		handlerFunc(w, r.WithContext(auth.WithSession(r.Context(), session)))
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > len("bearer ") && strings.EqualFold(header[:len("bearer ")], "bearer ") {
+		return strings.TrimSpace(header[len("bearer "):])
+	}
+	return ""
 }
 
 func handleMethod(method string, handlerFunc http.HandlerFunc) http.HandlerFunc {
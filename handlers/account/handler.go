@@ -2,31 +2,80 @@ package account
 
 import (
 	"context"
-	"github.com/agnivade/levenshtein"
+	"strings"
+	"sync"
+	"time"
+
 	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/auth"
+	"github.com/danvixent/buycoin-challenge2/nuban"
 	"github.com/danvixent/buycoin-challenge2/password"
-	"github.com/danvixent/buycoin-challenge2/providers/paystack"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	ErrBankAccountNotFound = errors.New("bank account not found")
+	ErrUnknownBankCode     = errors.New("unknown bank code")
+	ErrProviderUnavailable = errors.New("bank account provider unavailable, please try again later")
+	ErrResolutionFailed    = errors.New("failed to resolve user bank account")
+	ErrEmailTaken          = errors.New("email already registered")
+)
+
 type Handler struct {
-	userRepo          app.UserRepository
-	paystackAPIClient *paystack.APIClient
+	userRepo         app.UserRepository
+	verificationRepo app.VerificationRepository
+	identityRepo     app.IdentityRepository
+	bankRepo         app.BankRepository
+	sessionRepo      app.SessionRepository
+	refreshTokenRepo app.RefreshTokenRepository
+	accountResolver  app.BankAccountResolver
+	bvnResolver      app.BVNResolver
+	nameMatcher      NameMatcher
+	tokenManager     *auth.TokenManager
+	hasher           password.Hasher
+	dummyHashOnce    sync.Once
+	dummyHash        *password.Hash
 }
 
-func NewHandler(userRepo app.UserRepository, paystackAPIClient *paystack.APIClient) *Handler {
-	return &Handler{userRepo: userRepo, paystackAPIClient: paystackAPIClient}
+func NewHandler(userRepo app.UserRepository, verificationRepo app.VerificationRepository, identityRepo app.IdentityRepository, bankRepo app.BankRepository, sessionRepo app.SessionRepository, refreshTokenRepo app.RefreshTokenRepository, accountResolver app.BankAccountResolver, bvnResolver app.BVNResolver, nameMatcher NameMatcher, tokenManager *auth.TokenManager, hasher password.Hasher) *Handler {
+	return &Handler{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		identityRepo:     identityRepo,
+		bankRepo:         bankRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		accountResolver:  accountResolver,
+		bvnResolver:      bvnResolver,
+		nameMatcher:      nameMatcher,
+		tokenManager:     tokenManager,
+		hasher:           hasher,
+	}
 }
 
 func (h *Handler) RegisterUser(ctx context.Context, input *UserRegistrationVM, logger *log.Entry) (*app.User, error) {
+	// emails are stored lowercased, and the unique index on lower(email) also stops registrations racing this check
+	email := strings.ToLower(strings.TrimSpace(input.Email))
+	if _, err := h.userRepo.FindUserByEmail(ctx, email); err == nil {
+		return nil, ErrEmailTaken
+	}
+
 	user := &app.User{
 		Name:     input.Name,
-		Email:    input.Email,
+		Email:    email,
 		Verified: false,
 	}
 
-	hash, err := password.NewPasswordHash(input.Password)
+	if input.DateOfBirth != nil && *input.DateOfBirth != "" {
+		dateOfBirth, err := time.Parse(dateOfBirthLayout, *input.DateOfBirth)
+		if err != nil {
+			return nil, ErrInvalidDateOfBirth
+		}
+		user.DateOfBirth = &dateOfBirth
+	}
+
+	hash, err := h.hasher.Hash(input.Password)
 	if err != nil {
 		logger.WithError(err).WithField("password_string", input.Password).Error("failed to generate password hash")
 		return nil, errors.Wrap(err, "failed to generate password hash")
@@ -46,15 +95,24 @@ func (h *Handler) AddBankAccount(ctx context.Context, userID string, account app
 		return false, errors.Wrap(err, "failed to find user by id")
 	}
 
-	r := &paystack.ResolveBankAccountRequest{
-		AccountNumber: account.UserAccountNumber,
+	err = h.checkAccountNumber(ctx, account.UserBankCode, account.UserAccountNumber, logger)
+	if err != nil {
+		return false, err
+	}
+
+	attempt := &app.VerificationAttempt{
+		UserID:        user.ID,
 		BankCode:      account.UserBankCode,
+		AccountNumber: account.UserAccountNumber,
+		SubmittedName: account.UserAccountName,
 	}
 
-	data, err := h.paystackAPIClient.ResolveBankAccount(ctx, r)
+	resolved, err := h.accountResolver.ResolveAccount(ctx, account.UserBankCode, account.UserAccountNumber)
 	if err != nil {
 		logger.WithError(err).Errorf("failed to resolve bank account")
-		return false, errors.New("failed to resolve user bank account")
+		attempt.Decision = app.DecisionResolutionFailed
+		h.saveVerificationAttempt(ctx, attempt, logger)
+		return false, resolutionError(err)
 	}
 
 	userBankAccount := &app.UserBankAccount{
@@ -63,19 +121,120 @@ func (h *Handler) AddBankAccount(ctx context.Context, userID string, account app
 		User:        user,
 	}
 
-	if data.AccountName == account.UserAccountName {
-		return h.verifyUser(ctx, userBankAccount)
+	result := h.nameMatcher.Match(account.UserAccountName, resolved.AccountName)
+	logger.WithFields(log.Fields{
+		"provider": resolved.Provider,
+		"strategy": result.Strategy,
+		"score":    result.Score,
+		"distance": result.Distance,
+		"decision": result.Decision,
+	}).Info("account name match")
+
+	if resolved.Disagreement != nil && result.Decision == app.DecisionApproved {
+		logger.WithField("other_provider", resolved.Disagreement.Provider).Info("providers disagree on account name, queueing for review")
+		result.Decision = app.DecisionNeedsReview
 	}
 
-	distance := levenshtein.ComputeDistance(data.AccountName, account.UserAccountName)
-	if distance <= 2 {
+	attempt.ResolvedName = resolved.AccountName
+	attempt.Provider = resolved.Provider
+	attempt.Strategy = result.Strategy
+	attempt.Score = result.Score
+	attempt.Distance = result.Distance
+	attempt.Decision = result.Decision
+	h.saveVerificationAttempt(ctx, attempt, logger)
+
+	switch result.Decision {
+	case app.DecisionApproved:
 		return h.verifyUser(ctx, userBankAccount)
+	case app.DecisionNeedsReview:
+		return h.queueForReview(ctx, userBankAccount)
+	default:
+		return false, errors.New("failed to add bank account")
+	}
+}
+
+// checkAccountNumber rejects bank codes missing from the bank directory and account numbers
+// that aren't valid NUBANs for their bank without asking a provider. Banks the directory lists
+// with another type of account number, like mobile money, skip the NUBAN check
+func (h *Handler) checkAccountNumber(ctx context.Context, bankCode string, accountNumber string, logger *log.Entry) error {
+	bank, err := h.findBank(ctx, bankCode, logger)
+	if err != nil {
+		return err
 	}
 
-	return false, errors.New("failed to add bank account")
+	if bank != nil && bank.Type != app.BankTypeNUBAN {
+		return nil
+	}
+
+	// the check digit of banks listed under codes other than their CBN code can't be computed,
+	// so their account numbers are left for the provider to check
+	if !nuban.HasCheckDigit(bankCode) {
+		return nuban.ValidateAccountNumber(accountNumber)
+	}
+	return nuban.Validate(bankCode, accountNumber)
+}
+
+// findBank returns the bank with bankCode from the bank directory, or ErrUnknownBankCode when it
+// isn't listed. No bank is returned and every code is let through until the directory has been
+// synced, and when it can't be read
+func (h *Handler) findBank(ctx context.Context, bankCode string, logger *log.Entry) (*app.Bank, error) {
+	bank, err := h.bankRepo.FindBankByCode(ctx, bankCode)
+	if err == nil {
+		return bank, nil
+	}
+
+	if errors.Cause(err) != app.ErrUnknownBankCode {
+		logger.WithError(err).Warn("failed to find bank, skipping bank code check")
+		return nil, nil
+	}
+
+	count, err := h.bankRepo.CountBanks(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("failed to count banks, skipping bank code check")
+		return nil, nil
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+	return nil, ErrUnknownBankCode
+}
+
+// resolutionError tells users whether their account doesn't exist or the
+// provider is having trouble, without leaking provider details
+func resolutionError(err error) error {
+	switch errors.Cause(err) {
+	case app.ErrAccountNotFound:
+		return ErrBankAccountNotFound
+	case app.ErrUnknownBankCode:
+		return ErrUnknownBankCode
+	case app.ErrProviderUnavailable:
+		return ErrProviderUnavailable
+	default:
+		return ErrResolutionFailed
+	}
+}
+
+// saveVerificationAttempt stores the attempt for support purposes, failing to store
+// it must not fail the request so errors are only logged
+func (h *Handler) saveVerificationAttempt(ctx context.Context, attempt *app.VerificationAttempt, logger *log.Entry) {
+	err := h.verificationRepo.SaveVerificationAttempt(ctx, attempt)
+	if err != nil {
+		logger.WithError(err).Error("failed to save verification attempt")
+	}
+}
+
+func (h *Handler) FindVerificationAttempts(ctx context.Context, userID string, logger *log.Entry) ([]*app.VerificationAttempt, error) {
+	attempts, err := h.verificationRepo.FindVerificationAttemptsByUserID(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Error("failed to find verification attempts")
+		return nil, errors.New("find verification attempts failed")
+	}
+	return attempts, nil
 }
 
 func (h *Handler) verifyUser(ctx context.Context, userBankAccount *app.UserBankAccount) (bool, error) {
+	userBankAccount.Status = app.BankAccountStatusVerified
 	userBankAccount.User.Verified = true
 	err := h.userRepo.SaveUserBankAccount(ctx, userBankAccount)
 	if err != nil {
@@ -84,7 +243,103 @@ func (h *Handler) verifyUser(ctx context.Context, userBankAccount *app.UserBankA
 	return true, nil
 }
 
+// queueForReview saves the bank account as pending without verifying the user, the
+// false result without an error tells the caller the account is waiting for an admin
+func (h *Handler) queueForReview(ctx context.Context, userBankAccount *app.UserBankAccount) (bool, error) {
+	userBankAccount.Status = app.BankAccountStatusPending
+	err := h.userRepo.SaveUserBankAccount(ctx, userBankAccount)
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (h *Handler) FindPendingBankAccounts(ctx context.Context, logger *log.Entry) ([]*app.UserBankAccount, error) {
+	accounts, err := h.userRepo.FindUserBankAccountsByStatus(ctx, app.BankAccountStatusPending)
+	if err != nil {
+		logger.WithError(err).Error("failed to find pending bank accounts")
+		return nil, errors.New("find pending bank accounts failed")
+	}
+	return accounts, nil
+}
+
+// ApproveBankAccount verifies a bank account waiting for review along with its owner
+func (h *Handler) ApproveBankAccount(ctx context.Context, id string, logger *log.Entry) (bool, error) {
+	account, err := h.findPendingBankAccount(ctx, id, logger)
+	if err != nil {
+		return false, err
+	}
+
+	account.Status = app.BankAccountStatusVerified
+	account.User.Verified = true
+	err = h.userRepo.UpdateUserBankAccount(ctx, account)
+	if err != nil {
+		logger.WithError(err).Error("failed to approve bank account")
+		return false, errors.New("approve bank account failed")
+	}
+	return true, nil
+}
+
+// RejectBankAccount rejects a bank account waiting for review, its owner stays
+// verified only if they have some other verified bank account or a verified BVN
+func (h *Handler) RejectBankAccount(ctx context.Context, id string, logger *log.Entry) (bool, error) {
+	account, err := h.findPendingBankAccount(ctx, id, logger)
+	if err != nil {
+		return false, err
+	}
+
+	verified, err := h.hasVerification(ctx, account.UserID, logger)
+	if err != nil {
+		return false, errors.New("reject bank account failed")
+	}
+
+	account.Status = app.BankAccountStatusRejected
+	account.User.Verified = verified
+	err = h.userRepo.UpdateUserBankAccount(ctx, account)
+	if err != nil {
+		logger.WithError(err).Error("failed to reject bank account")
+		return false, errors.New("reject bank account failed")
+	}
+	return true, nil
+}
+
+// hasVerification reports whether a user has a verified bank account or a verified BVN
+func (h *Handler) hasVerification(ctx context.Context, userID string, logger *log.Entry) (bool, error) {
+	verified, err := h.userRepo.HasVerifiedUserBankAccount(ctx, userID)
+	if err != nil {
+		logger.WithError(err).Error("failed to check for verified bank accounts")
+		return false, err
+	}
+
+	if !verified {
+		verified, err = h.identityRepo.HasVerifiedUserIdentity(ctx, userID)
+		if err != nil {
+			logger.WithError(err).Error("failed to check for verified identities")
+			return false, err
+		}
+	}
+	return verified, nil
+}
+
+func (h *Handler) findPendingBankAccount(ctx context.Context, id string, logger *log.Entry) (*app.UserBankAccount, error) {
+	account, err := h.userRepo.FindUserBankAccountByID(ctx, id)
+	if err != nil {
+		logger.WithError(err).Error("failed to find user bank account")
+		return nil, errors.New("find user bank account failed")
+	}
+
+	if account.Status != app.BankAccountStatusPending {
+		return nil, errors.Errorf("bank account is %s, only pending bank accounts can be reviewed", account.Status)
+	}
+	return account, nil
+}
+
 func (h *Handler) ResolveAccount(ctx context.Context, bankCode string, accountNumber string, logger *log.Entry) (string, error) {
+	err := h.checkAccountNumber(ctx, bankCode, accountNumber, logger)
+	if err != nil {
+		return "", err
+	}
+
 	account, err := h.userRepo.FindUserBankAccount(ctx, bankCode, accountNumber)
 	if err != nil {
 		logger.WithError(err).Error("failed to find user bank account")
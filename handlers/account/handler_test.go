@@ -0,0 +1,93 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/nuban"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *stubUserRepository) SaveUserBankAccount(ctx context.Context, account *app.UserBankAccount) error {
+	return nil
+}
+
+// stubBankRepository serves a fixed list of banks by code
+type stubBankRepository struct {
+	app.BankRepository
+	banks []*app.Bank
+}
+
+func (s *stubBankRepository) FindBankByCode(ctx context.Context, code string) (*app.Bank, error) {
+	for _, bank := range s.banks {
+		if bank.Code == code {
+			return bank, nil
+		}
+	}
+	return nil, errors.Wrap(app.ErrUnknownBankCode, code)
+}
+
+func (s *stubBankRepository) CountBanks(ctx context.Context) (int64, error) {
+	return int64(len(s.banks)), nil
+}
+
+type stubVerificationRepository struct {
+	app.VerificationRepository
+}
+
+func (s *stubVerificationRepository) SaveVerificationAttempt(ctx context.Context, attempt *app.VerificationAttempt) error {
+	return nil
+}
+
+// stubAccountResolver resolves every account to name and counts the calls
+type stubAccountResolver struct {
+	name  string
+	calls int
+}
+
+func (s *stubAccountResolver) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	s.calls++
+	return &app.ResolvedBankAccount{AccountNumber: accountNumber, AccountName: s.name, BankCode: bankCode, Provider: "paystack"}, nil
+}
+
+func TestAddBankAccountCheckDigit(t *testing.T) {
+	banks := []*app.Bank{
+		{Code: "035", Name: "Wema Bank", Type: app.BankTypeNUBAN},
+		{Code: "035A", Name: "ALAT by WEMA", Type: app.BankTypeNUBAN},
+		{Code: "50211", Name: "Kuda Bank", Type: app.BankTypeNUBAN},
+	}
+
+	tests := []struct {
+		name          string
+		bankCode      string
+		accountNumber string
+		wantErr       error
+		wantCalls     int
+	}{
+		{name: "should_add_account_at_alat", bankCode: "035A", accountNumber: "0123456789", wantCalls: 1},
+		{name: "should_add_account_at_kuda", bankCode: "50211", accountNumber: "2001234567", wantCalls: 1},
+		{name: "should_add_account_passing_check_digit", bankCode: "035", accountNumber: "7811035832", wantCalls: 1},
+		{name: "should_reject_account_failing_check_digit", bankCode: "035", accountNumber: "7811035835", wantErr: nuban.ErrCheckDigitMismatch},
+		{name: "should_reject_short_account_without_check_digit", bankCode: "50211", accountNumber: "200123456", wantErr: nuban.ErrInvalidAccountNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &stubUserRepository{user: &app.User{ID: "user", Name: "Daniel Oluojomu"}}
+			resolver := &stubAccountResolver{name: "DANIEL OLUOJOMU"}
+			h := NewHandler(userRepo, &stubVerificationRepository{}, nil, &stubBankRepository{banks: banks}, nil, nil, resolver, nil, &stubNameMatcher{decision: app.DecisionApproved}, nil, nil)
+
+			ok, err := h.AddBankAccount(context.Background(), "user", app.BankAccount{
+				UserBankCode:      tt.bankCode,
+				UserAccountNumber: tt.accountNumber,
+				UserAccountName:   "Daniel Oluojomu",
+			}, log.WithField("test", "add_bank_account"))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantErr == nil, ok)
+			assert.Equal(t, tt.wantCalls, resolver.calls)
+		})
+	}
+}
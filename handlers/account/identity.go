@@ -0,0 +1,207 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	dateOfBirthLayout = "2006-01-02"
+	bvnLength         = 11
+	bvnVisibleDigits  = 4
+)
+
+var (
+	ErrInvalidBVN          = errors.New("bvn must be 11 digits")
+	ErrInvalidDateOfBirth  = errors.New("date of birth must be formatted as YYYY-MM-DD")
+	ErrDateOfBirthRequired = errors.New("date of birth is required to verify bvn")
+	ErrBVNNotFound         = errors.New("bvn not found")
+	ErrIdentityMismatch    = errors.New("bvn details do not match")
+	ErrBVNResolutionFailed = errors.New("failed to resolve bvn")
+	ErrIdentityUnavailable = errors.New("identity provider unavailable, please try again later")
+)
+
+// VerifyBVN compares the name and date of birth registered to a BVN with the user's. A matching
+// date of birth and an approved name verify the user, a name needing review leaves the identity
+// pending for an admin to approve or reject and returns false without an error, and anything else
+// fails with ErrIdentityMismatch. Users registered without a date of birth supply one with
+// dateOfBirth, which is stored once it matches the BVN's, and it's ignored for everyone else
+func (h *Handler) VerifyBVN(ctx context.Context, userID string, bvn string, dateOfBirth *string, logger *log.Entry) (bool, error) {
+	bvn = strings.TrimSpace(bvn)
+	if len(bvn) != bvnLength || strings.Trim(bvn, "0123456789") != "" {
+		return false, ErrInvalidBVN
+	}
+
+	user, err := h.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to find user by id")
+	}
+
+	suppliedDateOfBirth := false
+	if user.DateOfBirth == nil {
+		if dateOfBirth == nil {
+			return false, ErrDateOfBirthRequired
+		}
+
+		parsed, err := time.Parse(dateOfBirthLayout, *dateOfBirth)
+		if err != nil {
+			return false, ErrInvalidDateOfBirth
+		}
+		user.DateOfBirth = &parsed
+		suppliedDateOfBirth = true
+	}
+
+	resolved, err := h.bvnResolver.ResolveBVN(ctx, bvn)
+	if err != nil {
+		logger.WithError(err).Error("failed to resolve bvn")
+		switch errors.Cause(err) {
+		case app.ErrBVNNotFound:
+			return false, ErrBVNNotFound
+		case app.ErrProviderUnavailable:
+			return false, ErrIdentityUnavailable
+		default:
+			return false, ErrBVNResolutionFailed
+		}
+	}
+
+	registeredName := strings.Join(strings.Fields(strings.Join([]string{resolved.FirstName, resolved.MiddleName, resolved.LastName}, " ")), " ")
+	result := h.nameMatcher.Match(user.Name, registeredName)
+
+	decision := result.Decision
+	sameDateOfBirth := user.DateOfBirth.Format(dateOfBirthLayout) == resolved.DateOfBirth.Format(dateOfBirthLayout)
+	if !sameDateOfBirth {
+		decision = app.DecisionRejected
+	}
+
+	logger.WithFields(log.Fields{
+		"provider":           resolved.Provider,
+		"strategy":           result.Strategy,
+		"score":              result.Score,
+		"same_date_of_birth": sameDateOfBirth,
+		"decision":           decision,
+	}).Info("bvn identity match")
+
+	identity := &app.UserIdentity{
+		UserID:      user.ID,
+		MaskedBVN:   maskBVN(bvn),
+		FirstName:   resolved.FirstName,
+		MiddleName:  resolved.MiddleName,
+		LastName:    resolved.LastName,
+		DateOfBirth: resolved.DateOfBirth,
+		Provider:    resolved.Provider,
+		Strategy:    result.Strategy,
+		Score:       result.Score,
+		Decision:    decision,
+	}
+
+	switch decision {
+	case app.DecisionApproved:
+		identity.Status = app.IdentityStatusVerified
+	case app.DecisionNeedsReview:
+		identity.Status = app.IdentityStatusPending
+	default:
+		identity.Status = app.IdentityStatusRejected
+	}
+
+	err = h.identityRepo.SaveUserIdentity(ctx, identity)
+	if err != nil {
+		logger.WithError(err).Error("failed to save user identity")
+		return false, errors.New("verify bvn failed")
+	}
+
+	// a date of birth that doesn't match the BVN's isn't kept, so a typo can be corrected
+	if suppliedDateOfBirth && sameDateOfBirth {
+		err = h.userRepo.UpdateUser(ctx, user)
+		if err != nil {
+			logger.WithError(err).Error("failed to save date of birth")
+			return false, errors.New("verify bvn failed")
+		}
+	}
+
+	switch identity.Status {
+	case app.IdentityStatusVerified:
+		user.Verified = true
+		err = h.userRepo.UpdateUser(ctx, user)
+		if err != nil {
+			logger.WithError(err).Error("failed to verify user")
+			return false, errors.New("verify bvn failed")
+		}
+		return true, nil
+	case app.IdentityStatusPending:
+		return false, nil
+	default:
+		return false, ErrIdentityMismatch
+	}
+}
+
+func (h *Handler) FindPendingIdentities(ctx context.Context, logger *log.Entry) ([]*app.UserIdentity, error) {
+	identities, err := h.identityRepo.FindUserIdentitiesByStatus(ctx, app.IdentityStatusPending)
+	if err != nil {
+		logger.WithError(err).Error("failed to find pending identities")
+		return nil, errors.New("find pending identities failed")
+	}
+	return identities, nil
+}
+
+// ApproveIdentity verifies an identity waiting for review along with its owner
+func (h *Handler) ApproveIdentity(ctx context.Context, id string, logger *log.Entry) (bool, error) {
+	identity, err := h.findPendingIdentity(ctx, id, logger)
+	if err != nil {
+		return false, err
+	}
+
+	identity.Status = app.IdentityStatusVerified
+	identity.User.Verified = true
+	err = h.identityRepo.UpdateUserIdentity(ctx, identity)
+	if err != nil {
+		logger.WithError(err).Error("failed to approve identity")
+		return false, errors.New("approve identity failed")
+	}
+	return true, nil
+}
+
+// RejectIdentity rejects an identity waiting for review, its owner stays
+// verified only if they have a verified bank account or some other verified BVN
+func (h *Handler) RejectIdentity(ctx context.Context, id string, logger *log.Entry) (bool, error) {
+	identity, err := h.findPendingIdentity(ctx, id, logger)
+	if err != nil {
+		return false, err
+	}
+
+	verified, err := h.hasVerification(ctx, identity.UserID, logger)
+	if err != nil {
+		return false, errors.New("reject identity failed")
+	}
+
+	identity.Status = app.IdentityStatusRejected
+	identity.User.Verified = verified
+	err = h.identityRepo.UpdateUserIdentity(ctx, identity)
+	if err != nil {
+		logger.WithError(err).Error("failed to reject identity")
+		return false, errors.New("reject identity failed")
+	}
+	return true, nil
+}
+
+func (h *Handler) findPendingIdentity(ctx context.Context, id string, logger *log.Entry) (*app.UserIdentity, error) {
+	identity, err := h.identityRepo.FindUserIdentityByID(ctx, id)
+	if err != nil {
+		logger.WithError(err).Error("failed to find user identity")
+		return nil, errors.New("find user identity failed")
+	}
+
+	if identity.Status != app.IdentityStatusPending {
+		return nil, errors.Errorf("identity is %s, only pending identities can be reviewed", identity.Status)
+	}
+	return identity, nil
+}
+
+// maskBVN hides all but the last digits of a BVN
+func maskBVN(bvn string) string {
+	return strings.Repeat("*", len(bvn)-bvnVisibleDigits) + bvn[len(bvn)-bvnVisibleDigits:]
+}
@@ -0,0 +1,324 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubUserRepository serves one user and records the last update
+type stubUserRepository struct {
+	app.UserRepository
+	user                *app.User
+	updated             *app.User
+	verifiedBankAccount bool
+}
+
+func (s *stubUserRepository) FindUserByID(ctx context.Context, id string) (*app.User, error) {
+	if s.user == nil || s.user.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return s.user, nil
+}
+
+func (s *stubUserRepository) UpdateUser(ctx context.Context, user *app.User) error {
+	s.updated = user
+	return nil
+}
+
+func (s *stubUserRepository) HasVerifiedUserBankAccount(ctx context.Context, userID string) (bool, error) {
+	return s.verifiedBankAccount, nil
+}
+
+// stubIdentityRepository serves one identity and records the identities saved and updated
+type stubIdentityRepository struct {
+	app.IdentityRepository
+	identity         *app.UserIdentity
+	saved            *app.UserIdentity
+	updated          *app.UserIdentity
+	verifiedIdentity bool
+}
+
+func (s *stubIdentityRepository) SaveUserIdentity(ctx context.Context, identity *app.UserIdentity) error {
+	s.saved = identity
+	return nil
+}
+
+func (s *stubIdentityRepository) UpdateUserIdentity(ctx context.Context, identity *app.UserIdentity) error {
+	s.updated = identity
+	return nil
+}
+
+func (s *stubIdentityRepository) FindUserIdentityByID(ctx context.Context, id string) (*app.UserIdentity, error) {
+	if s.identity == nil || s.identity.ID != id {
+		return nil, errors.New("record not found")
+	}
+	return s.identity, nil
+}
+
+func (s *stubIdentityRepository) HasVerifiedUserIdentity(ctx context.Context, userID string) (bool, error) {
+	return s.verifiedIdentity, nil
+}
+
+type stubBVNResolver struct {
+	resolved *app.ResolvedBVN
+	err      error
+}
+
+func (s *stubBVNResolver) ResolveBVN(ctx context.Context, bvn string) (*app.ResolvedBVN, error) {
+	return s.resolved, s.err
+}
+
+// stubNameMatcher gives every pair of names the same decision
+type stubNameMatcher struct {
+	decision string
+}
+
+func (s *stubNameMatcher) Match(submitted string, resolved string) *MatchResult {
+	return &MatchResult{Strategy: StrategyToken, Score: 0.8, Decision: s.decision}
+}
+
+func TestVerifyBVN(t *testing.T) {
+	dateOfBirth := time.Date(1995, 6, 14, 0, 0, 0, 0, time.UTC)
+	resolved := &app.ResolvedBVN{FirstName: "DANIEL", LastName: "OLUOJOMU", DateOfBirth: dateOfBirth, Provider: "paystack"}
+
+	supplied := "1995-06-14"
+	otherSupplied := "1995-06-15"
+	invalidSupplied := "14/06/1995"
+
+	tests := []struct {
+		name                string
+		bvn                 string
+		dateOfBirth         *time.Time
+		suppliedDateOfBirth *string
+		resolved            *app.ResolvedBVN
+		resolveErr          error
+		decision            string
+		want                bool
+		wantErr             error
+		wantStatus          string
+		wantVerified        bool
+		wantDateOfBirth     *time.Time
+	}{
+		{
+			name:         "should_verify_user_for_approved_name_and_same_date_of_birth",
+			bvn:          "22212345678",
+			dateOfBirth:  &dateOfBirth,
+			resolved:     resolved,
+			decision:     app.DecisionApproved,
+			want:         true,
+			wantStatus:   app.IdentityStatusVerified,
+			wantVerified: true,
+		},
+		{
+			name:        "should_reject_approved_name_with_other_date_of_birth",
+			bvn:         "22212345678",
+			dateOfBirth: &dateOfBirth,
+			resolved: &app.ResolvedBVN{
+				FirstName:   "DANIEL",
+				LastName:    "OLUOJOMU",
+				DateOfBirth: dateOfBirth.AddDate(0, 0, 1),
+			},
+			decision:   app.DecisionApproved,
+			wantErr:    ErrIdentityMismatch,
+			wantStatus: app.IdentityStatusRejected,
+		},
+		{
+			name:        "should_leave_identity_pending_for_name_needing_review",
+			bvn:         "22212345678",
+			dateOfBirth: &dateOfBirth,
+			resolved:    resolved,
+			decision:    app.DecisionNeedsReview,
+			wantStatus:  app.IdentityStatusPending,
+		},
+		{
+			name:        "should_reject_other_name",
+			bvn:         "22212345678",
+			dateOfBirth: &dateOfBirth,
+			resolved:    resolved,
+			decision:    app.DecisionRejected,
+			wantErr:     ErrIdentityMismatch,
+			wantStatus:  app.IdentityStatusRejected,
+		},
+		{
+			name:        "should_error_for_unknown_bvn",
+			bvn:         "22212345678",
+			dateOfBirth: &dateOfBirth,
+			resolveErr:  errors.Wrap(app.ErrBVNNotFound, "status code 404"),
+			wantErr:     ErrBVNNotFound,
+		},
+		{
+			name:        "should_error_without_date_of_birth",
+			bvn:         "22212345678",
+			dateOfBirth: nil,
+			wantErr:     ErrDateOfBirthRequired,
+		},
+		{
+			name:                "should_store_supplied_date_of_birth_for_user_without_one",
+			bvn:                 "22212345678",
+			suppliedDateOfBirth: &supplied,
+			resolved:            resolved,
+			decision:            app.DecisionApproved,
+			want:                true,
+			wantStatus:          app.IdentityStatusVerified,
+			wantVerified:        true,
+			wantDateOfBirth:     &dateOfBirth,
+		},
+		{
+			name:                "should_store_supplied_date_of_birth_for_name_needing_review",
+			bvn:                 "22212345678",
+			suppliedDateOfBirth: &supplied,
+			resolved:            resolved,
+			decision:            app.DecisionNeedsReview,
+			wantStatus:          app.IdentityStatusPending,
+			wantDateOfBirth:     &dateOfBirth,
+		},
+		{
+			name:                "should_not_store_supplied_date_of_birth_not_matching_bvn",
+			bvn:                 "22212345678",
+			suppliedDateOfBirth: &otherSupplied,
+			resolved:            resolved,
+			decision:            app.DecisionApproved,
+			wantErr:             ErrIdentityMismatch,
+			wantStatus:          app.IdentityStatusRejected,
+		},
+		{
+			name:                "should_ignore_supplied_date_of_birth_for_user_with_one",
+			bvn:                 "22212345678",
+			dateOfBirth:         &dateOfBirth,
+			suppliedDateOfBirth: &otherSupplied,
+			resolved:            resolved,
+			decision:            app.DecisionApproved,
+			want:                true,
+			wantStatus:          app.IdentityStatusVerified,
+			wantVerified:        true,
+			wantDateOfBirth:     &dateOfBirth,
+		},
+		{
+			name:                "should_error_for_invalid_supplied_date_of_birth",
+			bvn:                 "22212345678",
+			suppliedDateOfBirth: &invalidSupplied,
+			wantErr:             ErrInvalidDateOfBirth,
+		},
+		{
+			name:        "should_error_for_invalid_bvn",
+			bvn:         "2221234567a",
+			dateOfBirth: &dateOfBirth,
+			wantErr:     ErrInvalidBVN,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &stubUserRepository{user: &app.User{ID: "user", Name: "Daniel Oluojomu", DateOfBirth: tt.dateOfBirth}}
+			identityRepo := &stubIdentityRepository{}
+			bvnResolver := &stubBVNResolver{resolved: tt.resolved, err: tt.resolveErr}
+			h := NewHandler(userRepo, nil, identityRepo, nil, nil, nil, nil, bvnResolver, &stubNameMatcher{decision: tt.decision}, nil, nil)
+
+			got, err := h.VerifyBVN(context.Background(), "user", tt.bvn, tt.suppliedDateOfBirth, log.WithField("test", "verify_bvn"))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantStatus == "" {
+				assert.Nil(t, identityRepo.saved)
+				return
+			}
+
+			if assert.NotNil(t, identityRepo.saved) {
+				assert.Equal(t, tt.wantStatus, identityRepo.saved.Status)
+				assert.Equal(t, "*******5678", identityRepo.saved.MaskedBVN)
+			}
+			assert.Equal(t, tt.wantVerified, userRepo.updated != nil && userRepo.updated.Verified)
+
+			switch {
+			case tt.wantDateOfBirth != nil:
+				if assert.NotNil(t, userRepo.updated) {
+					assert.True(t, tt.wantDateOfBirth.Equal(*userRepo.updated.DateOfBirth))
+				}
+			case tt.dateOfBirth == nil:
+				assert.Nil(t, userRepo.updated)
+			}
+		})
+	}
+}
+
+func TestReviewIdentity(t *testing.T) {
+	tests := []struct {
+		name                string
+		status              string
+		reject              bool
+		verifiedBankAccount bool
+		verifiedIdentity    bool
+		wantErr             bool
+		wantStatus          string
+		wantVerified        bool
+	}{
+		{
+			name:         "should_verify_user_on_approve",
+			status:       app.IdentityStatusPending,
+			wantStatus:   app.IdentityStatusVerified,
+			wantVerified: true,
+		},
+		{
+			name:       "should_leave_user_unverified_on_reject",
+			status:     app.IdentityStatusPending,
+			reject:     true,
+			wantStatus: app.IdentityStatusRejected,
+		},
+		{
+			name:                "should_keep_user_verified_by_bank_account_on_reject",
+			status:              app.IdentityStatusPending,
+			reject:              true,
+			verifiedBankAccount: true,
+			wantStatus:          app.IdentityStatusRejected,
+			wantVerified:        true,
+		},
+		{
+			name:             "should_keep_user_verified_by_another_bvn_on_reject",
+			status:           app.IdentityStatusPending,
+			reject:           true,
+			verifiedIdentity: true,
+			wantStatus:       app.IdentityStatusRejected,
+			wantVerified:     true,
+		},
+		{
+			name:    "should_error_for_identity_not_pending",
+			status:  app.IdentityStatusRejected,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &stubUserRepository{verifiedBankAccount: tt.verifiedBankAccount}
+			identityRepo := &stubIdentityRepository{
+				identity:         &app.UserIdentity{ID: "identity", UserID: "user", User: &app.User{ID: "user"}, Status: tt.status},
+				verifiedIdentity: tt.verifiedIdentity,
+			}
+			h := NewHandler(userRepo, nil, identityRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+
+			review := h.ApproveIdentity
+			if tt.reject {
+				review = h.RejectIdentity
+			}
+
+			ok, err := review(context.Background(), "identity", log.WithField("test", "review_identity"))
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, !tt.wantErr, ok)
+			if tt.wantErr {
+				assert.Nil(t, identityRepo.updated)
+				return
+			}
+
+			if assert.NotNil(t, identityRepo.updated) {
+				assert.Equal(t, tt.wantStatus, identityRepo.updated.Status)
+				assert.Equal(t, tt.wantVerified, identityRepo.updated.User.Verified)
+			}
+		})
+	}
+}
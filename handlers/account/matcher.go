@@ -0,0 +1,151 @@
+package account
+
+import (
+	"strings"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+)
+
+const (
+	StrategyLevenshtein        = "levenshtein"
+	StrategyDamerauLevenshtein = "damerau_levenshtein"
+	StrategyJaroWinkler        = "jaro_winkler"
+	StrategyTokenSet           = "token_set"
+	StrategyToken              = "token"
+	StrategyPhonetic           = "phonetic"
+)
+
+// defaultThresholds holds the minimum score each strategy needs to accept a match
+// when the config doesn't set one
+var defaultThresholds = map[string]float64{
+	StrategyLevenshtein:        0.85,
+	StrategyDamerauLevenshtein: 0.85,
+	StrategyJaroWinkler:        0.92,
+	StrategyTokenSet:           0.9,
+	StrategyToken:              0.85,
+	StrategyPhonetic:           0.85,
+}
+
+// MatchResult describes how closely the name a user submitted matched the name the bank returned
+type MatchResult struct {
+	Strategy string
+	// Score is a similarity between 0 and 1, 1 being identical names
+	Score float64
+	// Distance is the number of edits between both names, only edit distance strategies set it
+	Distance int
+	// Match is set when Score reached the strategy's threshold
+	Match bool
+	// Decision is one of app.DecisionApproved, app.DecisionNeedsReview or app.DecisionRejected
+	Decision string
+}
+
+// NameMatcher decides whether a submitted account name belongs to the same person as a resolved one
+type NameMatcher interface {
+	Match(submitted string, resolved string) *MatchResult
+}
+
+// NewNameMatcher returns the NameMatcher selected by cfg, a nil cfg selects Damerau-Levenshtein.
+// Names are normalized and names listed as variants of each other are treated
+// as equal before the selected strategy compares them, variants may be nil.
+// Matches are approved, scores between the review threshold and the threshold need a
+// manual review and anything lower is rejected
+func NewNameMatcher(cfg *config.NameMatcherConfig, variants *NameVariants) (NameMatcher, error) {
+	strategy := StrategyDamerauLevenshtein
+	threshold, reviewThreshold := 0.0, 0.0
+	var titles []string
+	if cfg != nil {
+		if cfg.Strategy != "" {
+			strategy = cfg.Strategy
+		}
+		threshold = cfg.Threshold
+		reviewThreshold = cfg.ReviewThreshold
+		titles = cfg.Titles
+	}
+
+	if threshold == 0 {
+		threshold = defaultThresholds[strategy]
+	}
+
+	if threshold < 0 || threshold > 1 {
+		return nil, errors.Errorf("name matcher threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	if reviewThreshold < 0 || reviewThreshold > threshold {
+		return nil, errors.Errorf("name matcher review threshold must be between 0 and the threshold %v, got %v", threshold, reviewThreshold)
+	}
+
+	matcher, err := newStrategyMatcher(strategy, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	return &normalizingMatcher{
+		normalizer:      newNameNormalizer(titles),
+		variants:        variants,
+		reviewThreshold: reviewThreshold,
+		matcher:         matcher,
+	}, nil
+}
+
+func newStrategyMatcher(strategy string, threshold float64) (NameMatcher, error) {
+	switch strategy {
+	case StrategyLevenshtein:
+		return &editDistanceMatcher{strategy: strategy, threshold: threshold, distance: levenshteinDistance}, nil
+	case StrategyDamerauLevenshtein:
+		return &editDistanceMatcher{strategy: strategy, threshold: threshold, distance: damerauLevenshteinDistance}, nil
+	case StrategyJaroWinkler:
+		return &similarityMatcher{strategy: strategy, threshold: threshold, similarity: jaroWinklerSimilarity}, nil
+	case StrategyTokenSet:
+		return &similarityMatcher{strategy: strategy, threshold: threshold, similarity: tokenSetRatio}, nil
+	case StrategyToken:
+		return &tokenMatcher{threshold: threshold}, nil
+	case StrategyPhonetic:
+		return &phoneticMatcher{threshold: threshold}, nil
+	default:
+		return nil, errors.Errorf("unknown name matcher strategy %q", strategy)
+	}
+}
+
+// editDistanceMatcher scores names by their edit distance relative to the longer name
+type editDistanceMatcher struct {
+	strategy  string
+	threshold float64
+	distance  func(a, b string) int
+}
+
+func (e *editDistanceMatcher) Match(submitted string, resolved string) *MatchResult {
+	submitted, resolved = prepareName(submitted), prepareName(resolved)
+
+	distance := e.distance(submitted, resolved)
+	score := distanceRatio(distance, submitted, resolved)
+	return &MatchResult{
+		Strategy: e.strategy,
+		Score:    score,
+		Distance: distance,
+		Match:    score >= e.threshold,
+	}
+}
+
+// similarityMatcher scores names with an algorithm that already yields a similarity between 0 and 1
+type similarityMatcher struct {
+	strategy   string
+	threshold  float64
+	similarity func(a, b string) float64
+}
+
+func (s *similarityMatcher) Match(submitted string, resolved string) *MatchResult {
+	submitted, resolved = prepareName(submitted), prepareName(resolved)
+
+	score := s.similarity(submitted, resolved)
+	return &MatchResult{
+		Strategy: s.strategy,
+		Score:    score,
+		Match:    score >= s.threshold,
+	}
+}
+
+// prepareName makes comparisons case insensitive and ignores repeated whitespace
+func prepareName(name string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+}
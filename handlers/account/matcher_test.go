@@ -0,0 +1,283 @@
+package account
+
+import (
+	"testing"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMatchers(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  string
+		submitted string
+		resolved  string
+		wantMatch bool
+	}{
+		{
+			name:      "levenshtein_should_match_case_insensitively",
+			strategy:  StrategyLevenshtein,
+			submitted: "Daniel Oluojomu",
+			resolved:  "DANIEL  OLUOJOMU",
+			wantMatch: true,
+		},
+		{
+			name:      "levenshtein_should_reject_short_different_names",
+			strategy:  StrategyLevenshtein,
+			submitted: "Ade Ola",
+			resolved:  "ADA OLU",
+			wantMatch: false,
+		},
+		{
+			name:      "damerau_levenshtein_should_match_adjacent_transposition",
+			strategy:  StrategyDamerauLevenshtein,
+			submitted: "Daniel Oluojmou",
+			resolved:  "DANIEL OLUOJOMU",
+			wantMatch: true,
+		},
+		{
+			name:      "damerau_levenshtein_should_tolerate_more_typos_in_long_names",
+			strategy:  StrategyDamerauLevenshtein,
+			submitted: "Oluwaseyi Adebayo Okonkwo",
+			resolved:  "OLUWASEYI ADEBAYOO OKONKOW",
+			wantMatch: true,
+		},
+		{
+			name:      "jaro_winkler_should_match_common_prefix_typo",
+			strategy:  StrategyJaroWinkler,
+			submitted: "Daniel Oluojomu",
+			resolved:  "DANIEL OLUOJOMY",
+			wantMatch: true,
+		},
+		{
+			name:      "jaro_winkler_should_reject_different_person",
+			strategy:  StrategyJaroWinkler,
+			submitted: "Daniel Oluojomu",
+			resolved:  "CHUKWUEMEKA OKAFOR",
+			wantMatch: false,
+		},
+		{
+			name:      "token_set_should_match_reordered_names",
+			strategy:  StrategyTokenSet,
+			submitted: "Daniel Oluojomu",
+			resolved:  "OLUOJOMU DANIEL",
+			wantMatch: true,
+		},
+		{
+			name:      "token_set_should_reject_different_person",
+			strategy:  StrategyTokenSet,
+			submitted: "Daniel Oluojomu",
+			resolved:  "CHUKWUEMEKA OKAFOR",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: tt.strategy}, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			result := matcher.Match(tt.submitted, tt.resolved)
+			assert.Equal(t, tt.strategy, result.Strategy)
+			assert.Equal(t, tt.wantMatch, result.Match, "score %v", result.Score)
+		})
+	}
+}
+
+func TestNewNameMatcher(t *testing.T) {
+	_, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: "soundex"}, nil)
+	assert.EqualError(t, err, `unknown name matcher strategy "soundex"`)
+
+	_, err = NewNameMatcher(&config.NameMatcherConfig{Threshold: 1.5}, nil)
+	assert.Error(t, err)
+
+	matcher, err := NewNameMatcher(nil, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, StrategyDamerauLevenshtein, matcher.Match("a", "a").Strategy)
+	}
+}
+
+func TestTokenMatcher(t *testing.T) {
+	matcher, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: StrategyToken}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name      string
+		submitted string
+		resolved  string
+		wantMatch bool
+	}{
+		{
+			name:      "should_match_identical_names",
+			submitted: "Daniel Oluojomu",
+			resolved:  "DANIEL OLUOJOMU",
+			wantMatch: true,
+		},
+		{
+			name:      "should_match_surname_first",
+			submitted: "Daniel Oluojomu",
+			resolved:  "OLUOJOMU DANIEL",
+			wantMatch: true,
+		},
+		{
+			name:      "should_match_missing_middle_name_on_submitted_side",
+			submitted: "Daniel Oluojomu",
+			resolved:  "OLUOJOMU DANIEL AYO",
+			wantMatch: true,
+		},
+		{
+			name:      "should_match_missing_middle_name_on_resolved_side",
+			submitted: "Chukwuemeka Ifeanyi Okafor",
+			resolved:  "OKAFOR CHUKWUEMEKA",
+			wantMatch: true,
+		},
+		{
+			name:      "should_match_middle_initial",
+			submitted: "Aisha B. Mohammed",
+			resolved:  "MOHAMMED AISHA BALARABE",
+			wantMatch: true,
+		},
+		{
+			name:      "should_ignore_punctuation",
+			submitted: "Adeyemi-Johnson, Tolulope",
+			resolved:  "TOLULOPE ADEYEMI JOHNSON",
+			wantMatch: true,
+		},
+		{
+			name:      "should_match_single_typo_in_long_token",
+			submitted: "Oluwaseun Adeleke",
+			resolved:  "ADELEKE OLUWASEUM",
+			wantMatch: true,
+		},
+		{
+			name:      "should_reject_two_missing_names",
+			submitted: "Daniel Oluojomu",
+			resolved:  "OLUOJOMU DANIEL AYODELE OLUWATOBI",
+			wantMatch: false,
+		},
+		{
+			name:      "should_reject_first_name_only",
+			submitted: "Daniel",
+			resolved:  "OLUOJOMU DANIEL",
+			wantMatch: false,
+		},
+		{
+			name:      "should_reject_shared_surname_only",
+			submitted: "Emeka Okafor",
+			resolved:  "OKAFOR NGOZI",
+			wantMatch: false,
+		},
+		{
+			name:      "should_reject_different_person",
+			submitted: "Ibrahim Musa",
+			resolved:  "FATIMA BELLO",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.Match(tt.submitted, tt.resolved)
+			assert.Equal(t, tt.wantMatch, result.Match, "score %v", result.Score)
+		})
+	}
+}
+
+func TestNameNormalizer(t *testing.T) {
+	normalizer := newNameNormalizer(nil)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "should_strip_diacritics", in: "Adéwálé Ọlátúnjí", want: "ADEWALE OLATUNJI"},
+		{name: "should_collapse_whitespace", in: "  Daniel \t Oluojomu  ", want: "DANIEL OLUOJOMU"},
+		{name: "should_remove_titles", in: "Chief Mrs. Ngozi Okafor", want: "NGOZI OKAFOR"},
+		{name: "should_keep_titles_after_the_first_name", in: "ADEWALE PRINCE BELLO", want: "ADEWALE PRINCE BELLO"},
+		{name: "should_keep_trailing_titles", in: "BELLO MUSA ALHAJI", want: "BELLO MUSA ALHAJI"},
+		{name: "should_keep_last_word_of_a_name_made_of_titles", in: "Chief Prince", want: "PRINCE"},
+		{name: "should_fold_compatibility_characters", in: "Ｄａｎｉｅｌ", want: "DANIEL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizer.Normalize(tt.in))
+		})
+	}
+}
+
+func TestNameMatcherNormalizesNames(t *testing.T) {
+	matcher, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: StrategyLevenshtein, Titles: []string{"Alhaji"}}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result := matcher.Match("Adéwálé  Bello", "ALHAJI ADEWALE BELLO")
+	assert.True(t, result.Match)
+	assert.Equal(t, 0, result.Distance)
+}
+
+func TestPhoneticMatcher(t *testing.T) {
+	matcher, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: StrategyPhonetic}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name      string
+		submitted string
+		resolved  string
+		wantMatch bool
+		wantScore float64
+	}{
+		{name: "should_match_identical_names_with_full_score", submitted: "Oluwaseyi Bello", resolved: "OLUWASEYI BELLO", wantMatch: true, wantScore: 1},
+		{name: "should_match_small_typo_by_spelling", submitted: "Oluwaseyii Ade", resolved: "OLUWASEYI ADE", wantMatch: true},
+		{name: "should_match_doubled_letters_by_sound", submitted: "Seyii Ojoo", resolved: "SEYI OJO", wantMatch: true, wantScore: phoneticConfidence},
+		{name: "should_match_dropped_w_by_sound", submitted: "Chuku Kwu", resolved: "CHUKWU KU", wantMatch: true, wantScore: phoneticConfidence},
+		{name: "should_match_francophone_spelling_by_sound", submitted: "Oumarou Ka", resolved: "UMARU KA", wantMatch: true, wantScore: phoneticConfidence},
+		{name: "should_match_silent_h", submitted: "Aishah Bala", resolved: "AISHA BALA", wantMatch: true},
+		{name: "should_reject_different_names", submitted: "Chinedu Eze", resolved: "CHIOMA OKEKE", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.Match(tt.submitted, tt.resolved)
+			assert.Equal(t, tt.wantMatch, result.Match, "score %v", result.Score)
+			if tt.wantScore != 0 {
+				assert.Equal(t, tt.wantScore, result.Score)
+			}
+		})
+	}
+}
+
+func TestPhoneticKey(t *testing.T) {
+	assert.Equal(t, phoneticKey("CHUKWUEMEKA"), phoneticKey("CHUKUEMEKA"))
+	assert.Equal(t, phoneticKey("OLUWASEYII"), phoneticKey("OLUWASEYI"))
+	assert.Equal(t, phoneticKey("OUMAROU"), phoneticKey("UMARU"))
+	assert.NotEqual(t, phoneticKey("CHINEDU"), phoneticKey("KINEDU"))
+}
+
+func TestNameMatcherDecisions(t *testing.T) {
+	matcher, err := NewNameMatcher(&config.NameMatcherConfig{
+		Strategy:        StrategyLevenshtein,
+		Threshold:       0.9,
+		ReviewThreshold: 0.7,
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, app.DecisionApproved, matcher.Match("Daniel Oluojomu", "DANIEL OLUOJOMU").Decision)
+	assert.Equal(t, app.DecisionNeedsReview, matcher.Match("Danny Oluojomu", "DANIEL OLUOJOMU").Decision)
+	assert.Equal(t, app.DecisionRejected, matcher.Match("Ibrahim Musa", "DANIEL OLUOJOMU").Decision)
+
+	_, err = NewNameMatcher(&config.NameMatcherConfig{Threshold: 0.8, ReviewThreshold: 0.9}, nil)
+	assert.Error(t, err)
+}
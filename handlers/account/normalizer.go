@@ -0,0 +1,77 @@
+package account
+
+import (
+	"strings"
+	"unicode"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultTitles are the honorifics stripped from the start of account names when the config doesn't list any
+var DefaultTitles = []string{
+	"MR", "MRS", "MS", "MISS", "MASTER", "DR", "PROF", "ENGR", "BARR", "ARC", "REV", "PASTOR",
+	"SIR", "CHIEF", "ALHAJI", "ALHAJA", "HAJIA", "MALLAM", "OTUNBA", "PRINCE", "PRINCESS", "OBA",
+}
+
+// nameNormalizer reduces a name to a canonical form before it is compared:
+// compatibility characters and diacritics are folded ("Adéwálé" becomes "ADEWALE"),
+// whitespace is collapsed and titles like "MR" or "CHIEF" leading the name are dropped
+type nameNormalizer struct {
+	titles map[string]bool
+}
+
+func newNameNormalizer(titles []string) *nameNormalizer {
+	if titles == nil {
+		titles = DefaultTitles
+	}
+
+	n := &nameNormalizer{titles: make(map[string]bool, len(titles))}
+	for _, title := range titles {
+		n.titles[strings.ToUpper(strings.TrimSpace(title))] = true
+	}
+	return n
+}
+
+func (n *nameNormalizer) Normalize(name string) string {
+	t := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, name)
+	if err != nil {
+		folded = name
+	}
+
+	// titles only lead a name, the same words further in, like PRINCE or OBA, are names. The last
+	// word is always kept so a name made up of titles isn't emptied
+	tokens := strings.Fields(strings.ToUpper(folded))
+	for len(tokens) > 1 && n.titles[strings.TrimRight(tokens[0], ".")] {
+		tokens = tokens[1:]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// normalizingMatcher normalizes both names and lines up known name variants before handing
+// them to the wrapped matcher, then sorts the result into a decision band.
+// A zero reviewThreshold disables manual reviews
+type normalizingMatcher struct {
+	normalizer      *nameNormalizer
+	variants        *NameVariants
+	reviewThreshold float64
+	matcher         NameMatcher
+}
+
+func (n *normalizingMatcher) Match(submitted string, resolved string) *MatchResult {
+	submitted, resolved = n.normalizer.Normalize(submitted), n.normalizer.Normalize(resolved)
+	result := n.matcher.Match(alignVariants(n.variants, submitted, resolved), resolved)
+
+	switch {
+	case result.Match:
+		result.Decision = app.DecisionApproved
+	case n.reviewThreshold > 0 && result.Score >= n.reviewThreshold:
+		result.Decision = app.DecisionNeedsReview
+	default:
+		result.Decision = app.DecisionRejected
+	}
+	return result
+}
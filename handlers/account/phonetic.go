@@ -0,0 +1,91 @@
+package account
+
+import (
+	"strings"
+	"unicode"
+)
+
+// phoneticConfidence scales the similarity of two phonetic keys, names that only
+// sound alike are accepted with a lower score than names that are spelt alike
+const phoneticConfidence = 0.9
+
+// phoneticReplacer folds spellings that sound the same in transliterated Yoruba, Igbo and Hausa
+// names, "CHUKWUEMEKA" and "CHUKUEMEKA" or "OUMAROU" and "UMARU" share a key
+var phoneticReplacer = strings.NewReplacer(
+	"PH", "F",
+	"TH", "T",
+	"GH", "G",
+	"DJ", "J",
+	"KW", "K",
+	"GW", "G",
+	"CK", "K",
+	"OU", "U",
+	"Q", "K",
+	"X", "KS",
+)
+
+// phoneticMatcher accepts names whose spelling is close enough like Damerau-Levenshtein does,
+// and falls back to comparing phonetic keys for names that are spelt differently but sound alike
+type phoneticMatcher struct {
+	threshold float64
+}
+
+func (p *phoneticMatcher) Match(submitted string, resolved string) *MatchResult {
+	submitted, resolved = prepareName(submitted), prepareName(resolved)
+
+	distance := damerauLevenshteinDistance(submitted, resolved)
+	score := distanceRatio(distance, submitted, resolved)
+	if score < p.threshold {
+		submittedKey, resolvedKey := phoneticKey(submitted), phoneticKey(resolved)
+		keyScore := distanceRatio(damerauLevenshteinDistance(submittedKey, resolvedKey), submittedKey, resolvedKey)
+		score = maxFloat(score, keyScore*phoneticConfidence)
+	}
+
+	return &MatchResult{
+		Strategy: StrategyPhonetic,
+		Score:    score,
+		Distance: distance,
+		Match:    score >= p.threshold,
+	}
+}
+
+// phoneticKey returns the phonetic key of every word in name, separated by spaces
+func phoneticKey(name string) string {
+	var keys []string
+	for _, token := range nameTokens(name) {
+		keys = append(keys, phoneticTokenKey(token))
+	}
+	return strings.Join(keys, " ")
+}
+
+func phoneticTokenKey(token string) string {
+	token = phoneticReplacer.Replace(token)
+	runes := []rune(token)
+
+	var b strings.Builder
+	var last rune
+	for i, r := range runes {
+		// a hard C is spelt K in most transliterations, CH is left alone
+		if r == 'C' && (i+1 == len(runes) || runes[i+1] != 'H') {
+			r = 'K'
+		}
+
+		// a trailing H after a vowel is silent, "AISHAH" sounds like "AISHA"
+		if r == 'H' && i+1 == len(runes) && i > 0 && isVowel(runes[i-1]) {
+			continue
+		}
+
+		// doubled letters are pronounced once, "OLUWASEYII" sounds like "OLUWASEYI"
+		if r == last {
+			continue
+		}
+
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("AEIOU", unicode.ToUpper(r))
+}
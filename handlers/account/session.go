@@ -0,0 +1,213 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/auth"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidCredentials is returned for unknown emails and wrong passwords alike,
+	// so failed logins don't tell which emails are registered
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
+
+// Login checks the user's password and starts a session for them, with a token they authenticate with
+// and a refresh token to renew it
+func (h *Handler) Login(ctx context.Context, email string, plaintext string, logger *log.Entry) (*app.Session, error) {
+	user, err := h.userRepo.FindUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		// verify anyway so unknown emails take as long to fail as wrong passwords
+		h.verifyDummyHash(plaintext)
+		logger.WithError(err).Info("login failed, user not found")
+		return nil, ErrInvalidCredentials
+	}
+
+	if !user.Password.Verify(plaintext) {
+		logger.WithField("user_id", user.ID).Info("login failed, wrong password")
+		return nil, ErrInvalidCredentials
+	}
+
+	if h.hasher.NeedsRehash(user.Password) {
+		h.rehashPassword(ctx, user, plaintext, logger)
+	}
+
+	session := &app.Session{
+		UserID:    user.ID,
+		User:      user,
+		ExpiresAt: time.Now().Add(h.tokenManager.RefreshExpiry()),
+	}
+
+	err = h.sessionRepo.CreateSession(ctx, session)
+	if err != nil {
+		logger.WithError(err).Error("failed to create session")
+		return nil, errors.New("login failed")
+	}
+
+	err = h.issueTokens(ctx, session)
+	if err != nil {
+		logger.WithError(err).Error("failed to issue tokens")
+		return nil, errors.New("login failed")
+	}
+	return session, nil
+}
+
+// RefreshSession swaps a refresh token for a new one and a new token for its session. A refresh
+// token that has already been used revokes its session, since either it or the token that replaced
+// it is in the wrong hands and we can't tell which
+func (h *Handler) RefreshSession(ctx context.Context, refreshToken string, logger *log.Entry) (*app.Session, error) {
+	token, err := h.refreshTokenRepo.FindRefreshTokenByHash(ctx, auth.HashOpaqueToken(refreshToken))
+	if err != nil {
+		logger.WithError(err).Info("refresh token not found")
+		return nil, ErrInvalidRefreshToken
+	}
+	logger = logger.WithField("session_id", token.SessionID)
+
+	session, err := h.sessionRepo.FindSessionByID(ctx, token.SessionID)
+	if err != nil {
+		logger.WithError(err).Error("failed to find session")
+		return nil, errors.New("refresh session failed")
+	}
+
+	now := time.Now()
+	if !session.Active(now) || !now.Before(token.ExpiresAt) {
+		logger.Info("refresh token for inactive session")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	unused := token.UsedAt == nil
+	if unused {
+		unused, err = h.refreshTokenRepo.MarkRefreshTokenUsed(ctx, token.ID, now)
+		if err != nil {
+			logger.WithError(err).Error("failed to mark refresh token used")
+			return nil, errors.New("refresh session failed")
+		}
+	}
+
+	if !unused {
+		logger.Warn("refresh token reused, revoking session")
+		err = h.sessionRepo.RevokeSession(ctx, session.ID, now)
+		if err != nil {
+			logger.WithError(err).Error("failed to revoke session")
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session.User, err = h.userRepo.FindUserByID(ctx, session.UserID)
+	if err != nil {
+		logger.WithError(err).Error("failed to find user")
+		return nil, errors.New("refresh session failed")
+	}
+
+	err = h.issueTokens(ctx, session)
+	if err != nil {
+		logger.WithError(err).Error("failed to issue tokens")
+		return nil, errors.New("refresh session failed")
+	}
+	return session, nil
+}
+
+// Authenticate returns the session a token was issued for, with its user, as long as
+// the session is still active
+func (h *Handler) Authenticate(ctx context.Context, token string, logger *log.Entry) (*app.Session, error) {
+	claims, err := h.tokenManager.Parse(token)
+	if err != nil {
+		logger.WithError(err).Info("rejected token")
+		return nil, errors.Cause(err)
+	}
+
+	session, err := h.sessionRepo.FindSessionByID(ctx, claims.SessionID)
+	if err != nil {
+		logger.WithError(err).WithField("session_id", claims.SessionID).Info("failed to find session")
+		return nil, auth.ErrInvalidToken
+	}
+
+	if !session.Active(time.Now()) || session.UserID != claims.Subject {
+		logger.WithField("session_id", session.ID).Info("rejected token for inactive session")
+		return nil, auth.ErrInvalidToken
+	}
+
+	session.User, err = h.userRepo.FindUserByID(ctx, session.UserID)
+	if err != nil {
+		logger.WithError(err).WithField("user_id", session.UserID).Error("failed to find user")
+		return nil, auth.ErrInvalidToken
+	}
+	return session, nil
+}
+
+// Logout revokes a session, the tokens and refresh tokens issued for it stop being accepted
+func (h *Handler) Logout(ctx context.Context, sessionID string, logger *log.Entry) (bool, error) {
+	err := h.sessionRepo.RevokeSession(ctx, sessionID, time.Now())
+	if err != nil {
+		logger.WithError(err).Error("failed to revoke session")
+		return false, errors.New("logout failed")
+	}
+	return true, nil
+}
+
+// LogoutAllSessions revokes every session of a user, logging them out on all their devices
+func (h *Handler) LogoutAllSessions(ctx context.Context, userID string, logger *log.Entry) (bool, error) {
+	err := h.sessionRepo.RevokeUserSessions(ctx, userID, time.Now())
+	if err != nil {
+		logger.WithError(err).Error("failed to revoke sessions")
+		return false, errors.New("logout failed")
+	}
+	return true, nil
+}
+
+// issueTokens signs a new token for the session and stores a new refresh token for it,
+// which expires with the session
+func (h *Handler) issueTokens(ctx context.Context, session *app.Session) error {
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate refresh token")
+	}
+
+	err = h.refreshTokenRepo.CreateRefreshToken(ctx, &app.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: auth.HashOpaqueToken(refreshToken),
+		ExpiresAt: session.ExpiresAt,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create refresh token")
+	}
+
+	session.Token, session.TokenExpiresAt, err = h.tokenManager.Issue(session)
+	if err != nil {
+		return err
+	}
+	session.RefreshToken = refreshToken
+	return nil
+}
+
+// rehashPassword replaces the user's password hash with one using the configured parameters while
+// the password is known, failing to must not fail the login so errors are only logged
+func (h *Handler) rehashPassword(ctx context.Context, user *app.User, plaintext string, logger *log.Entry) {
+	hash, err := h.hasher.Hash(plaintext)
+	if err != nil {
+		logger.WithError(err).WithField("user_id", user.ID).Error("failed to rehash password")
+		return
+	}
+
+	err = h.userRepo.UpdateUser(ctx, &app.User{ID: user.ID, Password: hash})
+	if err != nil {
+		logger.WithError(err).WithField("user_id", user.ID).Error("failed to save rehashed password")
+		return
+	}
+	user.Password = hash
+}
+
+// verifyDummyHash verifies plaintext against a hash with the configured parameters, so it takes as
+// long as verifying a real password
+func (h *Handler) verifyDummyHash(plaintext string) {
+	h.dummyHashOnce.Do(func() {
+		h.dummyHash, _ = h.hasher.Hash("not a real password")
+	})
+	h.dummyHash.Verify(plaintext)
+}
@@ -0,0 +1,208 @@
+package account
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/agnivade/levenshtein"
+)
+
+// levenshteinDistance returns the minimum number of single character insertions,
+// deletions and substitutions needed to transform a into b
+func levenshteinDistance(a, b string) int {
+	return levenshtein.ComputeDistance(a, b)
+}
+
+// damerauLevenshteinDistance is the optimal string alignment variant of the
+// Damerau-Levenshtein distance, it counts a transposition of two adjacent characters as one edit
+func damerauLevenshteinDistance(a, b string) int {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 {
+		return len(s2)
+	}
+
+	if len(s2) == 0 {
+		return len(s1)
+	}
+
+	d := make([][]int, len(s1)+1)
+	for i := range d {
+		d[i] = make([]int, len(s2)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s1); i++ {
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s1[i-1] == s2[j-2] && s1[i-2] == s2[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(s1)][len(s2)]
+}
+
+// jaroWinklerSimilarity returns a similarity between 0 and 1, giving extra weight
+// to strings that share a common prefix of up to 4 characters
+func jaroWinklerSimilarity(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := maxInt(len(s1), len(s2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+
+	matches := 0
+	for i := range s1 {
+		start := maxInt(0, i-window)
+		end := minInt(len(s2), i+window+1)
+		for j := start; j < end; j++ {
+			if matched2[j] || s1[i] != s2[j] {
+				continue
+			}
+			matched1[i], matched2[j] = true, true
+			matches++
+			break
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < minInt(4, len(s1), len(s2)); i++ {
+		if s1[i] != s2[i] {
+			break
+		}
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// tokenSetRatio compares the sorted intersection of the words in a and b against
+// the remaining words of each, so word order and extra words on one side don't count against the score
+func tokenSetRatio(a, b string) float64 {
+	tokens1, tokens2 := tokenSet(a), tokenSet(b)
+
+	var intersection, diff1, diff2 []string
+	for token := range tokens1 {
+		if tokens2[token] {
+			intersection = append(intersection, token)
+		} else {
+			diff1 = append(diff1, token)
+		}
+	}
+
+	for token := range tokens2 {
+		if !tokens1[token] {
+			diff2 = append(diff2, token)
+		}
+	}
+
+	sort.Strings(intersection)
+	sort.Strings(diff1)
+	sort.Strings(diff2)
+
+	t0 := strings.Join(intersection, " ")
+	t1 := strings.TrimSpace(t0 + " " + strings.Join(diff1, " "))
+	t2 := strings.TrimSpace(t0 + " " + strings.Join(diff2, " "))
+
+	ratio := editRatio(t1, t2)
+	if t0 != "" {
+		ratio = maxFloat(ratio, editRatio(t0, t1), editRatio(t0, t2))
+	}
+	return ratio
+}
+
+func tokenSet(s string) map[string]bool {
+	set := map[string]bool{}
+	for _, token := range strings.Fields(s) {
+		set[token] = true
+	}
+	return set
+}
+
+// editRatio turns the levenshtein distance between a and b into a similarity between 0 and 1
+func editRatio(a, b string) float64 {
+	return distanceRatio(levenshteinDistance(a, b), a, b)
+}
+
+// distanceRatio scales an edit distance by the length of the longer string,
+// so the same number of edits costs more on a short name than on a long one
+func distanceRatio(distance int, a, b string) float64 {
+	longest := maxInt(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(distance)/float64(longest)
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
+
+func maxFloat(values ...float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
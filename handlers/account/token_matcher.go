@@ -0,0 +1,123 @@
+package account
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// minTokenSimilarity is the lowest similarity at which two name tokens are considered the same name
+	minTokenSimilarity = 0.8
+	// initialSimilarity is the similarity given to an initial matching the first letter of a name
+	initialSimilarity = 0.9
+	// missingTokenPenalty is subtracted from the score for every name present on only one side
+	missingTokenPenalty = 0.1
+)
+
+// tokenMatcher compares names token by token, so "OLUOJOMU DANIEL AYO" matches
+// "Daniel Oluojomu": tokens may come in any order and one side may leave out a middle name
+type tokenMatcher struct {
+	threshold float64
+}
+
+func (t *tokenMatcher) Match(submitted string, resolved string) *MatchResult {
+	result := &MatchResult{Strategy: StrategyToken}
+
+	submittedTokens, resolvedTokens := nameTokens(submitted), nameTokens(resolved)
+	if len(submittedTokens) == 0 || len(resolvedTokens) == 0 {
+		return result
+	}
+
+	shorter, longer := submittedTokens, resolvedTokens
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+
+	// a lone first name is not enough to identify anyone unless the bank only has one name too
+	if len(shorter) == 1 && len(longer) > 1 {
+		return result
+	}
+
+	// only a single middle name may be missing from either side
+	missing := len(longer) - len(shorter)
+	if missing > 1 {
+		return result
+	}
+
+	total, ok := matchTokens(shorter, longer)
+	if !ok {
+		return result
+	}
+
+	result.Score = total/float64(len(shorter)) - float64(missing)*missingTokenPenalty
+	result.Match = result.Score >= t.threshold
+	return result
+}
+
+// matchTokens pairs every token in shorter with a distinct token in longer, always taking the
+// most similar remaining pair first. It returns the summed similarity of the pairs, and false if
+// some token in shorter has no counterpart
+func matchTokens(shorter, longer []string) (float64, bool) {
+	used := make([]bool, len(longer))
+	paired := make([]bool, len(shorter))
+
+	total := 0.0
+	for range shorter {
+		best, bestI, bestJ := 0.0, -1, -1
+		for i, a := range shorter {
+			if paired[i] {
+				continue
+			}
+			for j, b := range longer {
+				if used[j] {
+					continue
+				}
+				if s := tokenSimilarity(a, b); s > best {
+					best, bestI, bestJ = s, i, j
+				}
+			}
+		}
+
+		if best < minTokenSimilarity {
+			return 0, false
+		}
+
+		paired[bestI], used[bestJ] = true, true
+		total += best
+	}
+
+	return total, true
+}
+
+// tokenSimilarity compares two name tokens, treating a single letter as an initial
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 1 || len(rb) == 1 {
+		if ra[0] == rb[0] {
+			return initialSimilarity
+		}
+		return 0
+	}
+
+	return distanceRatio(damerauLevenshteinDistance(a, b), a, b)
+}
+
+// nameTokens uppercases name, drops apostrophes, treats any other punctuation as a separator
+// and splits what is left into words
+func nameTokens(name string) []string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		switch {
+		case r == '\'' || r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Fields(b.String())
+}
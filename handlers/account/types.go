@@ -1,7 +1,8 @@
 package account
 
 type UserRegistrationVM struct {
-	Name     string
-	Email    string
-	Password string
+	Name        string
+	Email       string
+	Password    string
+	DateOfBirth *string
 }
@@ -0,0 +1,184 @@
+package account
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// NameVariants is a dictionary of names that should be treated as the same name,
+// like "TUNDE" and "BABATUNDE" or "MIKE" and "MICHAEL".
+//
+// The dictionary is read from a YAML file mapping a name to its variants:
+//
+//	BABATUNDE: [TUNDE]
+//	MICHAEL: [MIKE, MIKEL]
+//
+// or from a CSV file where every row lists a name followed by its variants.
+// A name may appear in more than one group, "BAYO" is short for both "ADEBAYO" and "OLABAYO"
+type NameVariants struct {
+	path    string
+	mu      sync.RWMutex
+	groups  map[string][]int
+	modTime time.Time
+}
+
+// LoadNameVariants reads the dictionary at path, an empty path gives an empty dictionary
+func LoadNameVariants(path string) (*NameVariants, error) {
+	v := &NameVariants{path: path, groups: map[string][]int{}}
+	if path == "" {
+		return v, nil
+	}
+
+	if err := v.Reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Equivalent reports whether a and b are the same name or variants of each other
+func (v *NameVariants) Equivalent(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	if v == nil {
+		return false
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, i := range v.groups[a] {
+		for _, j := range v.groups[b] {
+			if i == j {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reload reads the dictionary file again and replaces the current entries with its contents
+func (v *NameVariants) Reload() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat name variants file")
+	}
+
+	file, err := os.Open(v.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open name variants file")
+	}
+	defer file.Close()
+
+	var entries [][]string
+	switch strings.ToLower(filepath.Ext(v.path)) {
+	case ".csv":
+		entries, err = readCSVVariants(file)
+	default:
+		entries, err = readYAMLVariants(file)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to decode name variants file")
+	}
+
+	groups := map[string][]int{}
+	for i, names := range entries {
+		for _, name := range names {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			groups[name] = append(groups[name], i)
+		}
+	}
+
+	v.mu.Lock()
+	v.groups = groups
+	v.modTime = info.ModTime()
+	v.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the dictionary whenever its file changes, checking every interval until ctx is done
+func (v *NameVariants) Watch(ctx context.Context, interval time.Duration, logger *log.Entry) {
+	if v.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(v.path)
+			if err != nil {
+				logger.WithError(err).Error("failed to stat name variants file")
+				continue
+			}
+
+			v.mu.RLock()
+			changed := !info.ModTime().Equal(v.modTime)
+			v.mu.RUnlock()
+			if !changed {
+				continue
+			}
+
+			if err = v.Reload(); err != nil {
+				logger.WithError(err).Error("failed to reload name variants")
+				continue
+			}
+			logger.Info("reloaded name variants")
+		}
+	}
+}
+
+func readYAMLVariants(r io.Reader) ([][]string, error) {
+	m := map[string][]string{}
+	if err := yaml.NewDecoder(r).Decode(&m); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	entries := make([][]string, 0, len(m))
+	for name, variants := range m {
+		entries = append(entries, append([]string{name}, variants...))
+	}
+	return entries, nil
+}
+
+func readCSVVariants(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	return reader.ReadAll()
+}
+
+// alignVariants rewrites every token of submitted that is a known variant of a token
+// in resolved to that resolved token, so strategies comparing whole strings see them as equal
+func alignVariants(variants *NameVariants, submitted string, resolved string) string {
+	submittedTokens := strings.Fields(submitted)
+	resolvedTokens := strings.Fields(resolved)
+
+	for i, token := range submittedTokens {
+		for _, candidate := range resolvedTokens {
+			if token != candidate && variants.Equivalent(token, candidate) {
+				submittedTokens[i] = candidate
+				break
+			}
+		}
+	}
+	return strings.Join(submittedTokens, " ")
+}
@@ -0,0 +1,92 @@
+package account
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameVariants(t *testing.T) {
+	variants, err := LoadNameVariants("../../config/name_variants.yml")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	matcher, err := NewNameMatcher(&config.NameMatcherConfig{Strategy: StrategyToken}, variants)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name      string
+		submitted string
+		resolved  string
+		wantMatch bool
+	}{
+		{name: "should_match_tunde_babatunde", submitted: "Tunde Bakare", resolved: "BAKARE BABATUNDE", wantMatch: true},
+		{name: "should_match_bayo_adebayo", submitted: "Bayo Ogunlesi", resolved: "OGUNLESI ADEBAYO", wantMatch: true},
+		{name: "should_match_mike_michael", submitted: "Mike Obi", resolved: "OBI MICHAEL CHINEDU", wantMatch: true},
+		{name: "should_match_spelling_variant", submitted: "Muhammad Sani", resolved: "SANI MOHAMMED", wantMatch: true},
+		{name: "should_not_match_unrelated_names", submitted: "Tunde Bakare", resolved: "BAKARE ADEBAYO", wantMatch: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matcher.Match(tt.submitted, tt.resolved)
+			assert.Equal(t, tt.wantMatch, result.Match, "score %v", result.Score)
+		})
+	}
+}
+
+func TestNameVariantsReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "variants")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "variants.csv")
+	err = ioutil.WriteFile(path, []byte("BABATUNDE,TUNDE\n"), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	variants, err := LoadNameVariants(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, variants.Equivalent("TUNDE", "BABATUNDE"))
+	assert.False(t, variants.Equivalent("KUNLE", "ADEKUNLE"))
+
+	err = ioutil.WriteFile(path, []byte("# reloaded\nADEKUNLE,KUNLE\n"), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// make sure the modification time moves even on filesystems with coarse timestamps
+	err = os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go variants.Watch(ctx, 10*time.Millisecond, log.WithField("test", t.Name()))
+
+	assert.Eventually(t, func() bool {
+		return variants.Equivalent("KUNLE", "ADEKUNLE")
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, variants.Equivalent("TUNDE", "BABATUNDE"))
+}
+
+func TestNilNameVariants(t *testing.T) {
+	var variants *NameVariants
+	assert.True(t, variants.Equivalent("TUNDE", "TUNDE"))
+	assert.False(t, variants.Equivalent("TUNDE", "BABATUNDE"))
+}
@@ -0,0 +1,170 @@
+package bank
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/danvixent/buycoin-challenge2/nuban"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultSuggestionConcurrency = 4
+
+type Handler struct {
+	bankRepo              app.BankRepository
+	directory             app.BankDirectory
+	accountResolver       app.BankAccountResolver
+	suggestionConcurrency int
+}
+
+// NewHandler creates a bank handler keeping the banks in bankRepo in sync with directory,
+// accountResolver confirms the banks suggested for account numbers
+func NewHandler(bankRepo app.BankRepository, directory app.BankDirectory, accountResolver app.BankAccountResolver, cfg *config.BankDirectoryConfig) *Handler {
+	h := &Handler{
+		bankRepo:              bankRepo,
+		directory:             directory,
+		accountResolver:       accountResolver,
+		suggestionConcurrency: defaultSuggestionConcurrency,
+	}
+
+	if cfg != nil && cfg.SuggestionConcurrency > 0 {
+		h.suggestionConcurrency = cfg.SuggestionConcurrency
+	}
+	return h
+}
+
+// SyncBanks saves the banks listed by the directory and deactivates the ones it no longer lists
+func (h *Handler) SyncBanks(ctx context.Context, logger *log.Entry) error {
+	banks, err := h.directory.FetchBanks(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch banks")
+	}
+
+	// an empty listing is more likely a provider hiccup than every bank closing down
+	if len(banks) == 0 {
+		logger.Warn("bank directory listed no banks, keeping saved banks")
+		return nil
+	}
+
+	err = h.bankRepo.SaveBanks(ctx, banks)
+	if err != nil {
+		return errors.Wrap(err, "failed to save banks")
+	}
+
+	logger.WithField("banks", len(banks)).Info("synced bank directory")
+	return nil
+}
+
+// Sync syncs the banks right away and then every interval until ctx is done
+func (h *Handler) Sync(ctx context.Context, interval time.Duration, logger *log.Entry) {
+	if err := h.SyncBanks(ctx, logger); err != nil {
+		logger.WithError(err).Error("failed to sync bank directory")
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.SyncBanks(ctx, logger); err != nil {
+				logger.WithError(err).Error("failed to sync bank directory")
+			}
+		}
+	}
+}
+
+func (h *Handler) FindBanks(ctx context.Context, search string, logger *log.Entry) ([]*app.Bank, error) {
+	banks, err := h.bankRepo.FindBanks(ctx, search)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find banks")
+	}
+	return banks, nil
+}
+
+// SuggestBanks returns the banks accountNumber is a valid NUBAN for, along with the banks whose check
+// digit can't be computed from their code. With confirm set every candidate
+// is resolved, a few at a time, and banks the account was not found at are left out. Banks that
+// could not be asked are kept unconfirmed rather than hiding the right one
+func (h *Handler) SuggestBanks(ctx context.Context, accountNumber string, confirm bool, logger *log.Entry) ([]*app.BankSuggestion, error) {
+	if err := nuban.ValidateAccountNumber(accountNumber); err != nil {
+		return nil, err
+	}
+
+	banks, err := h.bankRepo.FindBanks(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find banks")
+	}
+
+	var suggestions []*app.BankSuggestion
+	for _, bank := range banks {
+		// banks whose check digit can't be computed might hold the account, so they're suggested too
+		if bank.Type == app.BankTypeNUBAN && (!nuban.HasCheckDigit(bank.Code) || nuban.IsValid(bank.Code, accountNumber)) {
+			suggestions = append(suggestions, &app.BankSuggestion{Bank: bank})
+		}
+	}
+
+	if !confirm {
+		return suggestions, nil
+	}
+	return h.confirmSuggestions(ctx, accountNumber, suggestions, logger), nil
+}
+
+// confirmSuggestions resolves the account at every suggested bank with at most suggestionConcurrency
+// requests in flight, keeping the order of the suggestions
+func (h *Handler) confirmSuggestions(ctx context.Context, accountNumber string, suggestions []*app.BankSuggestion, logger *log.Entry) []*app.BankSuggestion {
+	keep := make([]bool, len(suggestions))
+	semaphore := make(chan struct{}, h.suggestionConcurrency)
+
+	var wg sync.WaitGroup
+spawn:
+	for i, suggestion := range suggestions {
+		// wait for a slot before starting a goroutine, so there are never more than suggestionConcurrency
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			// banks left unconfirmed are kept, like those that failed to resolve
+			for j := i; j < len(suggestions); j++ {
+				keep[j] = true
+			}
+			break spawn
+		}
+
+		wg.Add(1)
+		go func(i int, suggestion *app.BankSuggestion) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			account, err := h.accountResolver.ResolveAccount(ctx, suggestion.Bank.Code, accountNumber)
+			switch {
+			case err == nil:
+				suggestion.AccountName = account.AccountName
+				suggestion.Confirmed = true
+				keep[i] = true
+			case errors.Cause(err) == app.ErrAccountNotFound:
+			default:
+				logger.WithError(err).WithField("bank_code", suggestion.Bank.Code).Warn("failed to confirm suggested bank")
+				keep[i] = true
+			}
+		}(i, suggestion)
+	}
+	wg.Wait()
+
+	var kept []*app.BankSuggestion
+	for i, suggestion := range suggestions {
+		if keep[i] {
+			kept = append(kept, suggestion)
+		}
+	}
+	return kept
+}
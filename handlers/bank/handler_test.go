@@ -0,0 +1,171 @@
+package bank
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/danvixent/buycoin-challenge2/nuban"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBankRepository serves a fixed list of banks
+type stubBankRepository struct {
+	app.BankRepository
+	banks []*app.Bank
+}
+
+func (s *stubBankRepository) FindBanks(ctx context.Context, search string) ([]*app.Bank, error) {
+	return s.banks, nil
+}
+
+// stubResolver resolves accounts at the banks in names, fails with errs and counts requests in flight
+type stubResolver struct {
+	names       map[string]string
+	errs        map[string]error
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (s *stubResolver) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&s.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&s.maxInFlight, max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if err, ok := s.errs[bankCode]; ok {
+		return nil, err
+	}
+	if name, ok := s.names[bankCode]; ok {
+		return &app.ResolvedBankAccount{AccountNumber: accountNumber, AccountName: name, BankCode: bankCode}, nil
+	}
+	return nil, errors.Wrap(app.ErrAccountNotFound, "not found")
+}
+
+func testBanks() []*app.Bank {
+	// 035 and 057 give every serial number the same check digit and 058 doesn't, the check digit
+	// of paystack's code for Kuda can't be computed and mobile money account numbers aren't NUBANs at all
+	return []*app.Bank{
+		{Code: "555", Name: "Mobile Money", Type: "mobile_money"},
+		{Code: "50211", Name: "Kuda Bank", Type: app.BankTypeNUBAN},
+		{Code: "058", Name: "Guaranty Trust Bank", Type: app.BankTypeNUBAN},
+		{Code: "035", Name: "Wema Bank", Type: app.BankTypeNUBAN},
+		{Code: "057", Name: "Zenith Bank", Type: app.BankTypeNUBAN},
+	}
+}
+
+func suggestedCodes(suggestions []*app.BankSuggestion) []string {
+	codes := []string{}
+	for _, suggestion := range suggestions {
+		codes = append(codes, suggestion.Bank.Code)
+	}
+	return codes
+}
+
+func TestSuggestBanks(t *testing.T) {
+	tests := []struct {
+		name          string
+		accountNumber string
+		confirm       bool
+		names         map[string]string
+		errs          map[string]error
+		wantCodes     []string
+		wantConfirmed []bool
+		wantErr       error
+	}{
+		{
+			name:          "should_suggest_banks_accepting_check_digit_and_banks_without_one",
+			accountNumber: "7811035832",
+			wantCodes:     []string{"50211", "035", "057"},
+			wantConfirmed: []bool{false, false, false},
+		},
+		{
+			name:          "should_keep_only_confirmed_banks",
+			accountNumber: "7811035832",
+			confirm:       true,
+			names:         map[string]string{"035": "DANIEL OLUOJOMU"},
+			wantCodes:     []string{"035"},
+			wantConfirmed: []bool{true},
+		},
+		{
+			name:          "should_keep_banks_that_could_not_be_asked",
+			accountNumber: "7811035832",
+			confirm:       true,
+			errs:          map[string]error{"057": errors.Wrap(app.ErrProviderUnavailable, "status code 503")},
+			wantCodes:     []string{"057"},
+			wantConfirmed: []bool{false},
+		},
+		{
+			name:          "should_reject_invalid_account_number",
+			accountNumber: "78110358",
+			wantErr:       nuban.ErrInvalidAccountNumber,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &stubResolver{names: tt.names, errs: tt.errs}
+			h := NewHandler(&stubBankRepository{banks: testBanks()}, nil, resolver, nil)
+
+			suggestions, err := h.SuggestBanks(context.Background(), tt.accountNumber, tt.confirm, log.WithField("test", "suggest_banks"))
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCodes, suggestedCodes(suggestions))
+				for i, suggestion := range suggestions {
+					assert.Equal(t, tt.wantConfirmed[i], suggestion.Confirmed)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestBanksBoundsConcurrency(t *testing.T) {
+	var banks []*app.Bank
+	for i := 0; i < 10; i++ {
+		// the same bank listed ten times gives ten suggestions to confirm
+		banks = append(banks, &app.Bank{Code: "035", Name: "Wema Bank", Type: app.BankTypeNUBAN})
+	}
+
+	resolver := &stubResolver{}
+	h := NewHandler(&stubBankRepository{banks: banks}, nil, resolver, &config.BankDirectoryConfig{SuggestionConcurrency: 2})
+
+	_, err := h.SuggestBanks(context.Background(), "7811035832", true, log.WithField("test", "suggest_banks"))
+	assert.NoError(t, err)
+	maxInFlight := atomic.LoadInt32(&resolver.maxInFlight)
+	assert.LessOrEqual(t, maxInFlight, int32(2))
+	assert.Greater(t, maxInFlight, int32(1))
+}
+
+func TestSuggestBanksStopsWhenContextDone(t *testing.T) {
+	banks := []*app.Bank{
+		{Code: "035", Name: "Wema Bank", Type: app.BankTypeNUBAN},
+		{Code: "035", Name: "Wema Bank", Type: app.BankTypeNUBAN},
+	}
+
+	resolver := &stubResolver{names: map[string]string{"035": "DANIEL OLUOJOMU"}}
+	h := NewHandler(&stubBankRepository{banks: banks}, nil, resolver, &config.BankDirectoryConfig{SuggestionConcurrency: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	suggestions, err := h.SuggestBanks(ctx, "7811035832", true, log.WithField("test", "suggest_banks"))
+	if assert.NoError(t, err) && assert.Len(t, suggestions, 2) {
+		// the first slot is free so one bank may still be resolved before the cancellation is seen
+		assert.False(t, suggestions[1].Confirmed)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&resolver.maxInFlight), int32(1))
+}
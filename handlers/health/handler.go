@@ -0,0 +1,68 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/danvixent/buycoin-challenge2/providers/resolver"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	healthEndpoint = "/health"
+
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+)
+
+type Handler struct {
+	breakers map[string]*resolver.CircuitBreaker
+	caches   map[string]*resolver.CachingResolver
+}
+
+// NewHandler creates a health handler reporting the state of the given circuit breakers
+// and the hits and misses of the given caches by name
+func NewHandler(breakers map[string]*resolver.CircuitBreaker, caches map[string]*resolver.CachingResolver) *Handler {
+	return &Handler{breakers: breakers, caches: caches}
+}
+
+type healthResponse struct {
+	Status          string                           `json:"status"`
+	CircuitBreakers map[string]resolver.BreakerStats `json:"circuit_breakers"`
+	Caches          map[string]resolver.CacheStats   `json:"caches"`
+}
+
+func (h *Handler) SetupRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(healthEndpoint, h.health)
+}
+
+// health always answers 200 as long as the server is up, an open circuit
+// only marks the service as degraded since the providers are out of our hands
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := &healthResponse{
+		Status:          statusOK,
+		CircuitBreakers: map[string]resolver.BreakerStats{},
+		Caches:          map[string]resolver.CacheStats{},
+	}
+	for name, breaker := range h.breakers {
+		stats := breaker.Stats()
+		if stats.State != resolver.StateClosed {
+			resp.Status = statusDegraded
+		}
+		resp.CircuitBreakers[name] = stats
+	}
+
+	for name, cache := range h.caches {
+		resp.Caches[name] = cache.Stats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.WithError(err).Error("failed to write health response")
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/danvixent/buycoin-challenge2/providers/resolver"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubResolver resolves every account to the same name, or fails with err
+type stubResolver struct {
+	err error
+}
+
+func (s *stubResolver) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &app.ResolvedBankAccount{AccountNumber: accountNumber, AccountName: "DANIEL OLUOJOMU", BankCode: bankCode}, nil
+}
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		resolverErr error
+		wantCode    int
+		wantStatus  string
+		wantState   string
+		wantCache   resolver.CacheStats
+	}{
+		{
+			name:       "should_report_ok_with_closed_circuit",
+			method:     http.MethodGet,
+			wantCode:   http.StatusOK,
+			wantStatus: statusOK,
+			wantState:  resolver.StateClosed,
+			wantCache:  resolver.CacheStats{Hits: 1, Misses: 1},
+		},
+		{
+			name:        "should_report_degraded_with_open_circuit",
+			method:      http.MethodGet,
+			resolverErr: errors.Wrap(app.ErrProviderUnavailable, "status code 503"),
+			wantCode:    http.StatusOK,
+			wantStatus:  statusDegraded,
+			wantState:   resolver.StateOpen,
+			wantCache:   resolver.CacheStats{Misses: 2},
+		},
+		{
+			name:     "should_reject_other_methods",
+			method:   http.MethodPost,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := resolver.NewCircuitBreaker(&stubResolver{err: tt.resolverErr}, &config.CircuitBreakerConfig{FailureThreshold: 1})
+			cache := resolver.NewCachingResolver(breaker, resolver.NewMemoryCache(10), nil, log.WithField("test", "health"))
+
+			// a miss and, when the account resolves, a hit
+			for i := 0; i < 2; i++ {
+				_, _ = cache.ResolveAccount(context.Background(), "035", "7811035832")
+			}
+
+			mux := http.NewServeMux()
+			NewHandler(map[string]*resolver.CircuitBreaker{"account_resolver": breaker}, map[string]*resolver.CachingResolver{"account_resolver": cache}).SetupRoutes(mux)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, healthEndpoint, nil))
+			if !assert.Equal(t, tt.wantCode, rec.Code) || tt.wantCode != http.StatusOK {
+				return
+			}
+
+			resp := &healthResponse{}
+			err := json.NewDecoder(rec.Body).Decode(resp)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			assert.Equal(t, tt.wantState, resp.CircuitBreakers["account_resolver"].State)
+			assert.Equal(t, tt.wantCache, resp.Caches["account_resolver"])
+		})
+	}
+}
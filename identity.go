@@ -0,0 +1,62 @@
+package buycoin_challenge2
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBVNNotFound is the cause of errors returned by a BVNResolver when the provider reports that the BVN does not exist
+var ErrBVNNotFound = errors.New("bvn not found")
+
+const (
+	IdentityStatusVerified = "verified"
+	IdentityStatusPending  = "pending"
+	IdentityStatusRejected = "rejected"
+)
+
+// ResolvedBVN is the identity a provider found registered to a bank verification number
+type ResolvedBVN struct {
+	BVN         string    `json:"bvn"`
+	FirstName   string    `json:"first_name"`
+	MiddleName  string    `json:"middle_name"`
+	LastName    string    `json:"last_name"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Provider    string    `json:"provider"`
+}
+
+// BVNResolver looks up the identity registered to a bank verification number with an external provider
+type BVNResolver interface {
+	ResolveBVN(ctx context.Context, bvn string) (*ResolvedBVN, error)
+}
+
+// UserIdentity records how the identity registered to a user's BVN compared with the user,
+// only the last digits of the BVN are kept
+type UserIdentity struct {
+	ID          string    `json:"id" gorm:"default:gen_random_uuid()"`
+	UserID      string    `json:"user_id"`
+	User        *User     `json:"user"`
+	MaskedBVN   string    `json:"masked_bvn" gorm:"column:masked_bvn"`
+	FirstName   string    `json:"first_name"`
+	MiddleName  string    `json:"middle_name"`
+	LastName    string    `json:"last_name"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Provider    string    `json:"provider"`
+	Strategy    string    `json:"strategy"`
+	Score       float64   `json:"score"`
+	Decision    string    `json:"decision"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type IdentityRepository interface {
+	SaveUserIdentity(ctx context.Context, identity *UserIdentity) error
+	UpdateUserIdentity(ctx context.Context, identity *UserIdentity) error
+	FindUserIdentityByID(ctx context.Context, id string) (*UserIdentity, error)
+	FindUserIdentitiesByUserID(ctx context.Context, userID string) ([]*UserIdentity, error)
+	FindUserIdentitiesByStatus(ctx context.Context, status string) ([]*UserIdentity, error)
+	HasVerifiedUserIdentity(ctx context.Context, userID string) (bool, error)
+	DeleteAllUserIdentities() error
+}
@@ -0,0 +1,133 @@
+// Package nuban validates Nigerian Uniform Bank Account Numbers with the CBN check digit algorithm.
+//
+// A NUBAN is a 9 digit serial number followed by a check digit. The check digit is computed from
+// the bank's 6 digit code followed by the serial number: every digit is multiplied by its weight
+// in 3, 7, 3 repeated, and the check digit is 10 minus the sum modulo 10, or 0 when that is 10.
+// 3 digit codes of deposit money banks are padded to 6 digits with 000 and 5 digit codes of other
+// financial institutions like microfinance banks with a leading 9.
+//
+// Only CBN institution codes work here. Providers like paystack list some banks under codes of their
+// own, like 035A for ALAT or 50211 for Kuda, so HasCheckDigit tells which of their codes can be checked.
+package nuban
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	accountNumberLength = 10
+	serialLength        = 9
+	bankCodeLength      = 6
+)
+
+var (
+	ErrInvalidAccountNumber = errors.New("account number must be 10 digits")
+	ErrInvalidBankCode      = errors.New("bank code must be 3, 5 or 6 digits")
+	ErrCheckDigitMismatch   = errors.New("account number is not valid for this bank")
+)
+
+var weights = [bankCodeLength + serialLength]int{3, 7, 3, 3, 7, 3, 3, 7, 3, 3, 7, 3, 3, 7, 3}
+
+// Validate checks that accountNumber is a NUBAN issued by the bank with bankCode
+func Validate(bankCode, accountNumber string) error {
+	if err := ValidateAccountNumber(accountNumber); err != nil {
+		return err
+	}
+
+	checkDigit, err := CheckDigit(bankCode, accountNumber[:serialLength])
+	if err != nil {
+		return err
+	}
+
+	if int(accountNumber[serialLength]-'0') != checkDigit {
+		return ErrCheckDigitMismatch
+	}
+	return nil
+}
+
+// ValidateAccountNumber checks that accountNumber has the 10 digits of a NUBAN, without checking its check digit
+func ValidateAccountNumber(accountNumber string) error {
+	if len(accountNumber) != accountNumberLength || !isDigits(accountNumber) {
+		return ErrInvalidAccountNumber
+	}
+	return nil
+}
+
+// HasCheckDigit reports whether account numbers at the bank with the provider's bankCode can be checked.
+// Only the 3 digit codes of deposit money banks are known to be the CBN codes account numbers are
+// built from, the codes providers list other institutions under don't give their check digits
+func HasCheckDigit(bankCode string) bool {
+	return len(bankCode) == 3 && isDigits(bankCode)
+}
+
+// IsValid reports whether accountNumber is a NUBAN issued by the bank with bankCode
+func IsValid(bankCode, accountNumber string) bool {
+	return Validate(bankCode, accountNumber) == nil
+}
+
+// CheckDigit returns the check digit of the 9 digit serial number at the bank with bankCode
+func CheckDigit(bankCode, serial string) (int, error) {
+	if len(serial) != serialLength || !isDigits(serial) {
+		return 0, ErrInvalidAccountNumber
+	}
+
+	code, err := normalizeBankCode(bankCode)
+	if err != nil {
+		return 0, err
+	}
+
+	digits := code + serial
+	sum := 0
+	for i := range digits {
+		sum += int(digits[i]-'0') * weights[i]
+	}
+
+	checkDigit := 10 - sum%10
+	if checkDigit == 10 {
+		checkDigit = 0
+	}
+	return checkDigit, nil
+}
+
+// ValidBankCodes returns the codes among bankCodes accountNumber is a valid NUBAN for,
+// to suggest which bank a user meant when their account number doesn't match their bank
+func ValidBankCodes(accountNumber string, bankCodes []string) []string {
+	var valid []string
+	for _, code := range bankCodes {
+		if IsValid(code, accountNumber) {
+			valid = append(valid, code)
+		}
+	}
+	return valid
+}
+
+// normalizeBankCode pads bankCode to the 6 digits used to compute check digits
+func normalizeBankCode(bankCode string) (string, error) {
+	if !isDigits(bankCode) {
+		return "", ErrInvalidBankCode
+	}
+
+	switch len(bankCode) {
+	case 3:
+		return "000" + bankCode, nil
+	case 5:
+		return "9" + bankCode, nil
+	case bankCodeLength:
+		return bankCode, nil
+	default:
+		return "", ErrInvalidBankCode
+	}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := range s {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
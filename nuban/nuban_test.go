@@ -0,0 +1,113 @@
+package nuban
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		bankCode      string
+		accountNumber string
+		wantErr       error
+	}{
+		{
+			name:          "should_accept_cbn_example",
+			bankCode:      "011",
+			accountNumber: "0000014579",
+		},
+		{
+			name:          "should_accept_deposit_money_bank",
+			bankCode:      "035",
+			accountNumber: "7811035832",
+		},
+		{
+			name:          "should_accept_check_digit_zero",
+			bankCode:      "058",
+			accountNumber: "0000000070",
+		},
+		{
+			name:          "should_accept_five_digit_bank_code",
+			bankCode:      "50211",
+			accountNumber: "1234567897",
+		},
+		{
+			name:          "should_accept_six_digit_bank_code",
+			bankCode:      "950211",
+			accountNumber: "1234567897",
+		},
+		{
+			name:          "should_reject_wrong_check_digit",
+			bankCode:      "035",
+			accountNumber: "7811035835",
+			wantErr:       ErrCheckDigitMismatch,
+		},
+		{
+			name:          "should_reject_account_at_another_bank",
+			bankCode:      "058",
+			accountNumber: "0000014579",
+			wantErr:       ErrCheckDigitMismatch,
+		},
+		{
+			name:          "should_reject_short_account_number",
+			bankCode:      "035",
+			accountNumber: "781103583",
+			wantErr:       ErrInvalidAccountNumber,
+		},
+		{
+			name:          "should_reject_non_digit_account_number",
+			bankCode:      "035",
+			accountNumber: "78110358A2",
+			wantErr:       ErrInvalidAccountNumber,
+		},
+		{
+			name:          "should_reject_invalid_bank_code",
+			bankCode:      "02",
+			accountNumber: "7811035832",
+			wantErr:       ErrInvalidBankCode,
+		},
+		{
+			name:          "should_reject_non_digit_bank_code",
+			bankCode:      "03A",
+			accountNumber: "7811035832",
+			wantErr:       ErrInvalidBankCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.bankCode, tt.accountNumber)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantErr == nil, IsValid(tt.bankCode, tt.accountNumber))
+		})
+	}
+}
+
+func TestValidBankCodes(t *testing.T) {
+	codes := []string{"011", "035", "058", "50211"}
+	assert.Equal(t, []string{"011"}, ValidBankCodes("0000014579", codes))
+	assert.Equal(t, []string{"035"}, ValidBankCodes("7811035832", codes))
+	assert.Empty(t, ValidBankCodes("12345", codes))
+}
+
+func TestHasCheckDigit(t *testing.T) {
+	tests := []struct {
+		name     string
+		bankCode string
+		want     bool
+	}{
+		{name: "should_check_deposit_money_bank", bankCode: "058", want: true},
+		{name: "should_skip_paystack_alat_code", bankCode: "035A"},
+		{name: "should_skip_five_digit_code", bankCode: "50211"},
+		{name: "should_skip_six_digit_code", bankCode: "999992"},
+		{name: "should_skip_empty_code", bankCode: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasCheckDigit(tt.bankCode))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package password
+
+import (
+	"fmt"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	algorithmArgon2id = "argon2id"
+	argon2idKeyLength = 32
+)
+
+// Argon2idParams are the argon2id cost parameters, Memory is in KiB
+type Argon2idParams struct {
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+// DefaultArgon2idParams are the second recommended option of RFC 9106, for when 2 GiB per hash is too much
+var DefaultArgon2idParams = Argon2idParams{Memory: 64 * 1024, Time: 3, Threads: 4}
+
+func (a Argon2idParams) Algorithm() string {
+	return algorithmArgon2id
+}
+
+func (a Argon2idParams) encode() string {
+	return fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, a.Memory, a.Time, a.Threads)
+}
+
+func (a Argon2idParams) key(password []byte, salt []byte, length int) ([]byte, error) {
+	return argon2.IDKey(password, salt, a.Time, a.Memory, a.Threads, uint32(length)), nil
+}
+
+func parseArgon2idParams(segments []string) (Argon2idParams, error) {
+	params := Argon2idParams{}
+	if len(segments) != 2 {
+		return params, errors.New("invalid argon2id parameters")
+	}
+
+	var version int
+	_, err := fmt.Sscanf(segments[0], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, errors.Errorf("unsupported argon2id version %q", segments[0])
+	}
+
+	_, err = fmt.Sscanf(segments[1], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads)
+	if err != nil || params.Time < 1 || params.Threads < 1 || params.Memory < 8*uint32(params.Threads) {
+		return params, errors.Errorf("invalid argon2id parameters %q", segments[1])
+	}
+	return params, nil
+}
+
+// Argon2idHasher creates argon2id password hashes
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher creates an Argon2idHasher using the parameters in cfg, DefaultArgon2idParams are
+// used for those not set
+func NewArgon2idHasher(cfg *config.Argon2idConfig) (*Argon2idHasher, error) {
+	params := DefaultArgon2idParams
+	if cfg != nil {
+		if cfg.Memory != 0 {
+			params.Memory = cfg.Memory
+		}
+		if cfg.Time != 0 {
+			params.Time = cfg.Time
+		}
+		if cfg.Threads != 0 {
+			params.Threads = cfg.Threads
+		}
+	}
+
+	// argon2 needs 8 KiB of memory for each thread
+	if params.Memory < 8*uint32(params.Threads) {
+		return nil, errors.Errorf("argon2id memory must be at least 8 KiB per thread, got %d KiB for %d threads", params.Memory, params.Threads)
+	}
+	return &Argon2idHasher{params: params}, nil
+}
+
+func (a *Argon2idHasher) Hash(password string) (*Hash, error) {
+	return newPasswordHash(password, a.params, argon2idKeyLength)
+}
+
+// NeedsRehash ignores Threads, which changes how the work is split up rather than how much there is
+func (a *Argon2idHasher) NeedsRehash(hash *Hash) bool {
+	params, ok := hash.Params.(Argon2idParams)
+	if !ok {
+		return true
+	}
+	return a.params.Memory > params.Memory || a.params.Time > params.Time || len(hash.Hash) < argon2idKeyLength
+}
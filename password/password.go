@@ -2,60 +2,184 @@ package password
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"database/sql/driver"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"github.com/danvixent/buycoin-challenge2/config"
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/scrypt"
 	"strings"
 )
 
+const saltLength = 32
+
+// Params are the cost parameters of a hashing algorithm, every hash carries the
+// parameters it was created with so it can be verified after they are changed
+type Params interface {
+	// Algorithm is the identifier of the algorithm in the PHC string format
+	Algorithm() string
+	// encode returns the parameter segments of the PHC string format
+	encode() string
+	key(password []byte, salt []byte, length int) ([]byte, error)
+}
+
+// Hash is a password hash along with the algorithm and parameters it was created with,
+// it's stored in the PHC string format: $<algorithm>$<parameters>$<salt>$<hash>
 type Hash struct {
-	Hash []byte
-	Salt []byte
+	Params Params
+	Hash   []byte
+	Salt   []byte
+}
+
+// Hasher creates password hashes with one algorithm and its configured parameters
+type Hasher interface {
+	Hash(password string) (*Hash, error)
+	// NeedsRehash reports whether hash was made with another algorithm or parameters weaker than
+	// the configured ones, so it should be replaced the next time the password is known
+	NeedsRehash(hash *Hash) bool
+}
+
+// NewHasher creates the Hasher for the algorithm set in cfg, scrypt is used when none is set
+func NewHasher(cfg *config.PasswordConfig) (Hasher, error) {
+	if cfg == nil {
+		return NewScryptHasher(nil)
+	}
+
+	switch cfg.Algorithm {
+	case "", algorithmScrypt:
+		return NewScryptHasher(cfg.Scrypt)
+	case algorithmArgon2id:
+		return NewArgon2idHasher(cfg.Argon2id)
+	default:
+		return nil, errors.Errorf("password hashing algorithm %q is unknown", cfg.Algorithm)
+	}
 }
 
 // Create a hash of a password and salt
-func createPasswordHash(password string, salt []byte) ([]byte, error) {
+func createPasswordHash(password string, salt []byte, params Params, length int) ([]byte, error) {
 	password = strings.TrimSpace(password)
+	return params.key([]byte(password), salt, length)
+}
 
-	hash, err := scrypt.Key([]byte(password), salt, 16384, 8, 1, 64)
+// NewPasswordHash hashes password with scrypt and DefaultScryptParams
+func NewPasswordHash(password string) (*Hash, error) {
+	return newPasswordHash(password, DefaultScryptParams, scryptKeyLength)
+}
+
+func newPasswordHash(password string, params Params, length int) (*Hash, error) {
+	salt := generateSalt()
+	hash, err := createPasswordHash(password, salt, params, length)
 	if err != nil {
 		return nil, err
 	}
-	return hash, nil
+
+	return &Hash{Params: params, Hash: hash, Salt: salt}, nil
 }
 
-func NewPasswordHash(password string) (*Hash, error) {
-	salt := generateSalt()
-	hash, err := createPasswordHash(password, salt)
+// Verify reports whether password is the one the hash was created from, the hashes are compared in
+// constant time so the time taken doesn't tell how much of a guessed password was right
+func (h *Hash) Verify(password string) bool {
+	if h == nil || h.Params == nil || len(h.Hash) == 0 {
+		return false
+	}
+
+	hash, err := createPasswordHash(password, h.Salt, h.Params, len(h.Hash))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, h.Hash) == 1
+}
+
+// String encodes the hash in the PHC string format
+func (h Hash) String() string {
+	if h.Params == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("$%s$%s$%s$%s",
+		h.Params.Algorithm(),
+		h.Params.encode(),
+		base64.RawStdEncoding.EncodeToString(h.Salt),
+		base64.RawStdEncoding.EncodeToString(h.Hash),
+	)
+}
+
+// Parse decodes a hash in the PHC string format
+func Parse(encoded string) (*Hash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return nil, errors.New("password hash is not in the PHC string format")
+	}
+
+	// the parameters can take more than one segment, like the version of argon2id
+	segments := parts[2 : len(parts)-2]
+
+	var params Params
+	var err error
+	switch parts[1] {
+	case algorithmScrypt:
+		params, err = parseScryptParams(segments)
+	case algorithmArgon2id:
+		params, err = parseArgon2idParams(segments)
+	default:
+		return nil, errors.Errorf("unsupported password hash algorithm %q", parts[1])
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &Hash{Hash: hash, Salt: salt}, nil
+	salt, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-2])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid password hash salt")
+	}
+
+	hash, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid password hash")
+	}
+	return &Hash{Params: params, Hash: hash, Salt: salt}, nil
 }
 
 // Generate a random salt of length 32
 func generateSalt() []byte {
-	salt := make([]byte, 32)
+	salt := make([]byte, saltLength)
 
 	_, _ = rand.Read(salt)
 	return salt
 }
 
-// Value get value of Jsonb
+// Value stores the hash in the PHC string format
 func (h Hash) Value() (driver.Value, error) {
-	j, err := json.Marshal(h)
-	return j, err
+	return h.String(), nil
 }
 
-// Scan scan value into Hash
+// Scan reads a hash in the PHC string format, or the JSON object scrypt hashes created with
+// DefaultScryptParams were stored as before
 func (h *Hash) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
-		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	var encoded string
+	switch v := value.(type) {
+	case []byte:
+		encoded = string(v)
+	case string:
+		encoded = v
+	default:
+		return errors.New(fmt.Sprint("Failed to scan password hash:", value))
+	}
+
+	if strings.HasPrefix(encoded, "{") {
+		legacy := struct{ Hash, Salt []byte }{}
+		if err := json.Unmarshal([]byte(encoded), &legacy); err != nil {
+			return err
+		}
+		*h = Hash{Params: DefaultScryptParams, Hash: legacy.Hash, Salt: legacy.Salt}
+		return nil
 	}
 
-	return json.Unmarshal(bytes, h)
+	parsed, err := Parse(encoded)
+	if err != nil {
+		return err
+	}
+	*h = *parsed
+	return nil
 }
@@ -0,0 +1,240 @@
+package password
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	hash, err := NewPasswordHash("correct horse battery staple")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{name: "should_accept_same_password", password: "correct horse battery staple", want: true},
+		{name: "should_ignore_surrounding_whitespace", password: " correct horse battery staple ", want: true},
+		{name: "should_reject_other_password", password: "correct horse battery", want: false},
+		{name: "should_reject_empty_password", password: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hash.Verify(tt.password))
+		})
+	}
+
+	var missing *Hash
+	assert.False(t, missing.Verify("correct horse battery staple"))
+}
+
+func TestParse(t *testing.T) {
+	scryptHash, err := newPasswordHash("correct horse battery staple", ScryptParams{N: 1024, R: 4, P: 2}, scryptKeyLength)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	argon2idHash, err := newPasswordHash("correct horse battery staple", Argon2idParams{Memory: 1024, Time: 2, Threads: 1}, argon2idKeyLength)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	roundTrips := []struct {
+		name    string
+		hash    *Hash
+		pattern string
+	}{
+		{name: "should_round_trip_scrypt", hash: scryptHash, pattern: `^\$scrypt\$ln=10,r=4,p=2\$[A-Za-z0-9+/]{43}\$[A-Za-z0-9+/]{86}$`},
+		{name: "should_round_trip_argon2id", hash: argon2idHash, pattern: `^\$argon2id\$v=19\$m=1024,t=2,p=1\$[A-Za-z0-9+/]{43}\$[A-Za-z0-9+/]{43}$`},
+	}
+
+	for _, tt := range roundTrips {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.hash.String()
+			assert.Regexp(t, tt.pattern, encoded)
+
+			parsed, err := Parse(encoded)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.hash, parsed)
+				assert.True(t, parsed.Verify("correct horse battery staple"))
+				assert.False(t, parsed.Verify("correct horse battery"))
+			}
+		})
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "should_reject_other_format", encoded: "correct horse battery staple"},
+		{name: "should_reject_other_algorithm", encoded: "$bcrypt$ln=10,r=4,p=2$c2FsdA$aGFzaA"},
+		{name: "should_reject_missing_parameters", encoded: "$scrypt$r=4,p=2$c2FsdA$aGFzaA"},
+		{name: "should_reject_invalid_salt", encoded: "$scrypt$ln=10,r=4,p=2$c2F*dA$aGFzaA"},
+		{name: "should_reject_invalid_hash", encoded: "$scrypt$ln=10,r=4,p=2$c2FsdA$aGF*aA"},
+		{name: "should_reject_argon2id_without_version", encoded: "$argon2id$m=1024,t=2,p=1$c2FsdA$aGFzaA"},
+		{name: "should_reject_other_argon2id_version", encoded: "$argon2id$v=16$m=1024,t=2,p=1$c2FsdA$aGFzaA"},
+		{name: "should_reject_argon2id_without_memory", encoded: "$argon2id$v=19$m=0,t=2,p=1$c2FsdA$aGFzaA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.encoded)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	hash, err := NewPasswordHash("correct horse battery staple")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	value, err := hash.Value()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	legacy, err := json.Marshal(struct{ Hash, Salt []byte }{hash.Hash, hash.Salt})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "should_scan_phc_string", value: value},
+		{name: "should_scan_phc_bytes", value: []byte(value.(string))},
+		{name: "should_scan_legacy_json", value: legacy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanned := &Hash{}
+			if assert.NoError(t, scanned.Scan(tt.value)) {
+				assert.Equal(t, hash, scanned)
+			}
+		})
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.PasswordConfig
+		want    Hasher
+		wantErr bool
+	}{
+		{
+			name: "should_default_to_scrypt",
+			cfg:  nil,
+			want: &ScryptHasher{params: DefaultScryptParams},
+		},
+		{
+			name: "should_default_unset_scrypt_parameters",
+			cfg:  &config.PasswordConfig{Scrypt: &config.ScryptConfig{N: 32768}},
+			want: &ScryptHasher{params: ScryptParams{N: 32768, R: 8, P: 1}},
+		},
+		{
+			name: "should_select_argon2id",
+			cfg:  &config.PasswordConfig{Algorithm: "argon2id", Argon2id: &config.Argon2idConfig{Memory: 32 * 1024}},
+			want: &Argon2idHasher{params: Argon2idParams{Memory: 32 * 1024, Time: 3, Threads: 4}},
+		},
+		{
+			name:    "should_error_for_unknown_algorithm",
+			cfg:     &config.PasswordConfig{Algorithm: "md5"},
+			wantErr: true,
+		},
+		{
+			name:    "should_error_for_n_not_power_of_two",
+			cfg:     &config.PasswordConfig{Scrypt: &config.ScryptConfig{N: 30000}},
+			wantErr: true,
+		},
+		{
+			name:    "should_error_for_negative_r",
+			cfg:     &config.PasswordConfig{Scrypt: &config.ScryptConfig{R: -1}},
+			wantErr: true,
+		},
+		{
+			name:    "should_error_for_too_little_argon2id_memory",
+			cfg:     &config.PasswordConfig{Algorithm: "argon2id", Argon2id: &config.Argon2idConfig{Memory: 16, Threads: 4}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher, err := NewHasher(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, hasher)
+			}
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	scryptHasher := &ScryptHasher{params: ScryptParams{N: 32768, R: 8, P: 1}}
+	argon2idHasher := &Argon2idHasher{params: Argon2idParams{Memory: 64 * 1024, Time: 3, Threads: 4}}
+
+	tests := []struct {
+		name   string
+		hasher Hasher
+		params Params
+		length int
+		want   bool
+	}{
+		{name: "should_rehash_lower_scrypt_n", hasher: scryptHasher, params: DefaultScryptParams, length: scryptKeyLength, want: true},
+		{name: "should_rehash_lower_scrypt_r", hasher: scryptHasher, params: ScryptParams{N: 32768, R: 4, P: 1}, length: scryptKeyLength, want: true},
+		{name: "should_keep_same_scrypt_parameters", hasher: scryptHasher, params: ScryptParams{N: 32768, R: 8, P: 1}, length: scryptKeyLength, want: false},
+		{name: "should_keep_stronger_scrypt_parameters", hasher: scryptHasher, params: ScryptParams{N: 65536, R: 8, P: 2}, length: scryptKeyLength, want: false},
+		{name: "should_rehash_argon2id_with_scrypt", hasher: scryptHasher, params: DefaultArgon2idParams, length: argon2idKeyLength, want: true},
+		{name: "should_rehash_scrypt_with_argon2id", hasher: argon2idHasher, params: ScryptParams{N: 1 << 20, R: 8, P: 1}, length: scryptKeyLength, want: true},
+		{name: "should_rehash_lower_argon2id_memory", hasher: argon2idHasher, params: Argon2idParams{Memory: 32 * 1024, Time: 3, Threads: 4}, length: argon2idKeyLength, want: true},
+		{name: "should_rehash_lower_argon2id_time", hasher: argon2idHasher, params: Argon2idParams{Memory: 64 * 1024, Time: 2, Threads: 4}, length: argon2idKeyLength, want: true},
+		{name: "should_keep_argon2id_with_other_threads", hasher: argon2idHasher, params: Argon2idParams{Memory: 64 * 1024, Time: 3, Threads: 1}, length: argon2idKeyLength, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := &Hash{Params: tt.params, Hash: make([]byte, tt.length), Salt: make([]byte, saltLength)}
+			assert.Equal(t, tt.want, tt.hasher.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestMigrateScryptToArgon2id(t *testing.T) {
+	scryptHash, err := NewPasswordHash("correct horse battery staple")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	hasher, err := NewHasher(&config.PasswordConfig{Algorithm: "argon2id", Argon2id: &config.Argon2idConfig{Memory: 1024, Time: 1, Threads: 1}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// scrypt hashes stay verifiable after switching to argon2id, and are replaced once verified
+	assert.True(t, scryptHash.Verify("correct horse battery staple"))
+	assert.True(t, hasher.NeedsRehash(scryptHash))
+
+	argon2idHash, err := hasher.Hash("correct horse battery staple")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "argon2id", argon2idHash.Params.Algorithm())
+	assert.True(t, argon2idHash.Verify("correct horse battery staple"))
+	assert.False(t, hasher.NeedsRehash(argon2idHash))
+}
@@ -0,0 +1,95 @@
+package password
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	algorithmScrypt = "scrypt"
+	scryptKeyLength = 64
+)
+
+// ScryptParams are the scrypt cost parameters, N is the CPU and memory cost and must be a power of two
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+// DefaultScryptParams were used for every hash before parameters could be configured
+var DefaultScryptParams = ScryptParams{N: 16384, R: 8, P: 1}
+
+func (s ScryptParams) Algorithm() string {
+	return algorithmScrypt
+}
+
+// encode writes N as its base 2 logarithm
+func (s ScryptParams) encode() string {
+	return fmt.Sprintf("ln=%d,r=%d,p=%d", bits.TrailingZeros(uint(s.N)), s.R, s.P)
+}
+
+func (s ScryptParams) key(password []byte, salt []byte, length int) ([]byte, error) {
+	return scrypt.Key(password, salt, s.N, s.R, s.P, length)
+}
+
+func parseScryptParams(segments []string) (ScryptParams, error) {
+	var ln uint
+	params := ScryptParams{}
+	if len(segments) != 1 {
+		return params, errors.New("invalid scrypt parameters")
+	}
+
+	_, err := fmt.Sscanf(segments[0], "ln=%d,r=%d,p=%d", &ln, &params.R, &params.P)
+	if err != nil || ln < 1 || ln > 62 {
+		return params, errors.Errorf("invalid scrypt parameters %q", segments[0])
+	}
+	params.N = 1 << ln
+	return params, nil
+}
+
+// ScryptHasher creates scrypt password hashes
+type ScryptHasher struct {
+	params ScryptParams
+}
+
+// NewScryptHasher creates a ScryptHasher using the parameters in cfg, DefaultScryptParams are used for those not set
+func NewScryptHasher(cfg *config.ScryptConfig) (*ScryptHasher, error) {
+	params := DefaultScryptParams
+	if cfg != nil {
+		if cfg.N != 0 {
+			params.N = cfg.N
+		}
+		if cfg.R != 0 {
+			params.R = cfg.R
+		}
+		if cfg.P != 0 {
+			params.P = cfg.P
+		}
+	}
+
+	if params.N < 2 || params.N&(params.N-1) != 0 {
+		return nil, errors.Errorf("scrypt n must be a power of two above 1, got %d", params.N)
+	}
+
+	if params.R < 1 || params.P < 1 || uint64(params.R)*uint64(params.P) >= 1<<30 {
+		return nil, errors.Errorf("scrypt r and p must be positive and r*p below 2^30, got r=%d p=%d", params.R, params.P)
+	}
+	return &ScryptHasher{params: params}, nil
+}
+
+func (s *ScryptHasher) Hash(password string) (*Hash, error) {
+	return newPasswordHash(password, s.params, scryptKeyLength)
+}
+
+func (s *ScryptHasher) NeedsRehash(hash *Hash) bool {
+	params, ok := hash.Params.(ScryptParams)
+	if !ok {
+		return true
+	}
+	return s.params.N > params.N || s.params.R > params.R || s.params.P > params.P || len(hash.Hash) < scryptKeyLength
+}
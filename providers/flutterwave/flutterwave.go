@@ -0,0 +1,134 @@
+package flutterwave
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+)
+
+// ProviderName identifies flutterwave in resolved bank accounts
+const ProviderName = "flutterwave"
+
+const (
+	defaultBaseURL      = "https://api.flutterwave.com"
+	resolveAccountPath  = "/v3/accounts/resolve"
+	authorizationHeader = "Authorization"
+	statusSuccess       = "success"
+)
+
+type APIClient struct {
+	secretKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewAPIClient(cfg *config.FlutterwaveConfig) *APIClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+
+	return &APIClient{
+		secretKey:  cfg.SecretKey,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// ResolveBankAccount looks up the account on flutterwave. Errors caused by flutterwave being unreachable
+// or failing have app.ErrProviderUnavailable as their cause, and errors for accounts flutterwave
+// could not find have app.ErrAccountNotFound as their cause
+func (a *APIClient) ResolveBankAccount(ctx context.Context, account *ResolveAccountRequest) (*Data, error) {
+	body, err := json.Marshal(account)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+resolveAccountPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(authorizationHeader, "Bearer "+a.secretKey)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, errors.Wrapf(app.ErrProviderUnavailable, "flutterwave: %v", err)
+	}
+	defer resp.Body.Close()
+
+	responseData := &ResolveAccountResponse{}
+	decodeErr := getResponseData(resp.Body, responseData)
+
+	if err = mapError(resp.StatusCode, responseData); err != nil {
+		return nil, err
+	}
+
+	if decodeErr != nil {
+		return nil, errors.Wrap(decodeErr, "flutterwave: failed to decode response")
+	}
+
+	if responseData.Status != statusSuccess || responseData.Data == nil {
+		return nil, errors.Errorf("flutterwave: resolve account failed: %s", responseData.Message)
+	}
+	return responseData.Data, nil
+}
+
+// mapError turns unsuccessful responses into errors, returning nil for successful ones
+func mapError(statusCode int, response *ResolveAccountResponse) error {
+	switch {
+	case statusCode == http.StatusOK:
+		return nil
+	case statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError:
+		return errors.Wrapf(app.ErrProviderUnavailable, "flutterwave: status code %d", statusCode)
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return errors.Errorf("flutterwave: unauthorized: status code %d", statusCode)
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusNotFound:
+		// flutterwave answers accounts it can't find with a 400 and a message explaining why
+		if response.Message == "" {
+			return errors.Wrapf(app.ErrAccountNotFound, "flutterwave: status code %d", statusCode)
+		}
+		return errors.Wrapf(app.ErrAccountNotFound, "flutterwave: %s", response.Message)
+	default:
+		return errors.Errorf("flutterwave: resolve account failed: status code %d", statusCode)
+	}
+}
+
+// ResolveAccount implements app.BankAccountResolver
+func (a *APIClient) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	data, err := a.ResolveBankAccount(ctx, &ResolveAccountRequest{AccountNumber: accountNumber, AccountBank: bankCode})
+	if err != nil {
+		return nil, err
+	}
+
+	return &app.ResolvedBankAccount{
+		AccountNumber: data.AccountNumber,
+		AccountName:   data.AccountName,
+		BankCode:      bankCode,
+		Provider:      ProviderName,
+	}, nil
+}
+
+func getResponseData(respBody io.ReadCloser, data interface{}) error {
+	responseData, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(responseData, data); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package flutterwave
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecretKey = "FLWSECK_TEST-secret"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != resolveAccountPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get(authorizationHeader) != "Bearer "+testSecretKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"status":"error","message":"Invalid authorization key","data":null}`))
+			return
+		}
+
+		req := &ResolveAccountRequest{}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(req)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch req.AccountNumber {
+		case "0690000032":
+			_, _ = w.Write([]byte(`{"status":"success","message":"Account details fetched","data":{"account_number":"0690000032","account_name":"OLUOJOMU DANIEL"}}`))
+		case "5000000000":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`<html>upstream unavailable</html>`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","message":"Sorry, that account number is invalid, please check and try again","data":null}`))
+		}
+	}))
+}
+
+func TestResolveAccount(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		secretKey     string
+		accountNumber string
+		wantName      string
+		wantCause     error
+		wantErr       bool
+	}{
+		{
+			name:          "should_resolve_account",
+			secretKey:     testSecretKey,
+			accountNumber: "0690000032",
+			wantName:      "OLUOJOMU DANIEL",
+		},
+		{
+			name:          "should_map_invalid_account_to_not_found",
+			secretKey:     testSecretKey,
+			accountNumber: "0690000031",
+			wantErr:       true,
+			wantCause:     app.ErrAccountNotFound,
+		},
+		{
+			name:          "should_map_server_errors_to_provider_unavailable",
+			secretKey:     testSecretKey,
+			accountNumber: "5000000000",
+			wantErr:       true,
+			wantCause:     app.ErrProviderUnavailable,
+		},
+		{
+			name:          "should_error_for_wrong_secret_key",
+			secretKey:     "wrong",
+			accountNumber: "0690000032",
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewAPIClient(&config.FlutterwaveConfig{SecretKey: tt.secretKey, BaseURL: server.URL})
+
+			account, err := client.ResolveAccount(context.Background(), "044", tt.accountNumber)
+			if tt.wantErr {
+				if assert.Error(t, err) && tt.wantCause != nil {
+					assert.Equal(t, tt.wantCause, errors.Cause(err))
+				}
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantName, account.AccountName)
+			assert.Equal(t, tt.accountNumber, account.AccountNumber)
+			assert.Equal(t, "044", account.BankCode)
+			assert.Equal(t, ProviderName, account.Provider)
+		})
+	}
+}
+
+func TestResolveAccountUnreachable(t *testing.T) {
+	server := newTestServer(t)
+	server.Close()
+
+	client := NewAPIClient(&config.FlutterwaveConfig{SecretKey: testSecretKey, BaseURL: server.URL})
+	_, err := client.ResolveAccount(context.Background(), "044", "0690000032")
+	assert.Equal(t, app.ErrProviderUnavailable, errors.Cause(err))
+}
@@ -0,0 +1,17 @@
+package flutterwave
+
+type ResolveAccountRequest struct {
+	AccountNumber string `json:"account_number"`
+	AccountBank   string `json:"account_bank"`
+}
+
+type ResolveAccountResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Data    *Data  `json:"data"`
+}
+
+type Data struct {
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
@@ -0,0 +1,104 @@
+package paystack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/pkg/errors"
+)
+
+type ErrorKind string
+
+const (
+	// ErrorKindInvalidAccount means paystack could not find the account at the bank
+	ErrorKindInvalidAccount ErrorKind = "invalid_account"
+	// ErrorKindUnknownBankCode means paystack doesn't know the bank code
+	ErrorKindUnknownBankCode ErrorKind = "unknown_bank_code"
+	// ErrorKindUnauthorized means paystack rejected our API key
+	ErrorKindUnauthorized ErrorKind = "unauthorized"
+	// ErrorKindRateLimited means we sent paystack too many requests
+	ErrorKindRateLimited ErrorKind = "rate_limited"
+	// ErrorKindUpstreamUnavailable means paystack, or the bank behind it, could not be reached or failed
+	ErrorKindUpstreamUnavailable ErrorKind = "upstream_unavailable"
+	// ErrorKindUnexpected is any other failure
+	ErrorKindUnexpected ErrorKind = "unexpected"
+)
+
+// Error is returned for every request paystack did not fulfil, StatusCode is 0 when no response was received
+type Error struct {
+	Kind       ErrorKind
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("paystack: %s: %s", e.Kind, e.Message)
+	}
+	return fmt.Sprintf("paystack: %s: %s (status code %d)", e.Kind, e.Message, e.StatusCode)
+}
+
+// Temporary reports whether the request may succeed if sent again later
+func (e *Error) Temporary() bool {
+	return e.Kind == ErrorKindRateLimited || e.Kind == ErrorKindUpstreamUnavailable
+}
+
+// parseError builds an Error from an unsuccessful response, paystack explains
+// most failures in the message field of its JSON body
+func parseError(statusCode int, body []byte) *Error {
+	response := &ResolveBankAccountResponse{}
+	if err := json.Unmarshal(body, response); err != nil || response.Message == "" {
+		response.Message = http.StatusText(statusCode)
+	}
+
+	return &Error{
+		Kind:       classifyError(statusCode, response.Message),
+		StatusCode: statusCode,
+		Message:    response.Message,
+	}
+}
+
+func classifyError(statusCode int, message string) ErrorKind {
+	message = strings.ToLower(message)
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorKindUnauthorized
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return ErrorKindUpstreamUnavailable
+	case strings.Contains(message, "bank code") || strings.Contains(message, "unknown bank"):
+		return ErrorKindUnknownBankCode
+	case statusCode == http.StatusOK,
+		statusCode == http.StatusBadRequest,
+		statusCode == http.StatusNotFound,
+		statusCode == http.StatusUnprocessableEntity:
+		// paystack answers "Could not resolve account name. Check parameters or try again."
+		return ErrorKindInvalidAccount
+	default:
+		return ErrorKindUnexpected
+	}
+}
+
+// toAppError gives paystack errors the provider agnostic cause app.BankAccountResolver callers check for
+func toAppError(err error) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+
+	switch e.Kind {
+	case ErrorKindInvalidAccount:
+		return errors.Wrap(app.ErrAccountNotFound, e.Error())
+	case ErrorKindUnknownBankCode:
+		return errors.Wrap(app.ErrUnknownBankCode, e.Error())
+	case ErrorKindRateLimited, ErrorKindUpstreamUnavailable:
+		return errors.Wrap(app.ErrProviderUnavailable, e.Error())
+	default:
+		return e
+	}
+}
@@ -0,0 +1,90 @@
+package fake
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Fixtures are the banks and accounts served by the fake paystack server
+type Fixtures struct {
+	Banks      []*Bank     `yaml:"banks"`
+	Accounts   []*Account  `yaml:"accounts"`
+	Identities []*Identity `yaml:"identities"`
+}
+
+// Bank is served from /bank and accounts can only be resolved at listed banks
+type Bank struct {
+	ID       int    `yaml:"id" json:"id"`
+	Name     string `yaml:"name" json:"name"`
+	Slug     string `yaml:"slug" json:"slug"`
+	Code     string `yaml:"code" json:"code"`
+	LongCode string `yaml:"longcode" json:"longcode"`
+	Active   bool   `yaml:"active" json:"active"`
+	Country  string `yaml:"country" json:"country"`
+	Currency string `yaml:"currency" json:"currency"`
+	Type     string `yaml:"type" json:"type"`
+}
+
+// Account is resolved from /bank/resolve. Setting StatusCode makes resolving it fail
+// with that status code and Message, to exercise error handling for a single account
+type Account struct {
+	AccountNumber string `yaml:"account_number"`
+	AccountName   string `yaml:"account_name"`
+	BankCode      string `yaml:"bank_code"`
+	StatusCode    int    `yaml:"status_code"`
+	Message       string `yaml:"message"`
+}
+
+// Identity is resolved from /bank/resolve_bvn, DateOfBirth is formatted as YYYY-MM-DD
+type Identity struct {
+	BVN         string `yaml:"bvn"`
+	FirstName   string `yaml:"first_name"`
+	MiddleName  string `yaml:"middle_name"`
+	LastName    string `yaml:"last_name"`
+	DateOfBirth string `yaml:"date_of_birth"`
+	Mobile      string `yaml:"mobile"`
+}
+
+// LoadFixtures reads fixtures from the YAML file at path
+func LoadFixtures(path string) (*Fixtures, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open fixtures file")
+	}
+	defer file.Close()
+
+	fixtures := &Fixtures{}
+	if err = yaml.NewDecoder(file).Decode(fixtures); err != nil {
+		return nil, errors.Wrap(err, "failed to decode fixtures file")
+	}
+	return fixtures, nil
+}
+
+func (f *Fixtures) findBank(code string) *Bank {
+	for _, bank := range f.Banks {
+		if bank.Code == code {
+			return bank
+		}
+	}
+	return nil
+}
+
+func (f *Fixtures) findAccount(bankCode, accountNumber string) *Account {
+	for _, account := range f.Accounts {
+		if account.BankCode == bankCode && account.AccountNumber == accountNumber {
+			return account
+		}
+	}
+	return nil
+}
+
+func (f *Fixtures) findIdentity(bvn string) *Identity {
+	for _, identity := range f.Identities {
+		if identity.BVN == bvn {
+			return identity
+		}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package fake serves the parts of the paystack API this service uses from fixtures,
+// so tests and local development don't depend on paystack being reachable
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danvixent/buycoin-challenge2/providers/paystack"
+)
+
+const (
+	resolveBankAccountPath = "/bank/resolve"
+	listBanksPath          = "/bank"
+	resolveBVNPath         = "/bank/resolve_bvn/"
+
+	messageAccountResolved    = "Account number resolved"
+	messageBVNResolved        = "BVN resolved"
+	messageCouldNotResolveBVN = "Unable to resolve BVN"
+	messageBanksRetrieved     = "Banks retrieved"
+	messageCouldNotResolve    = "Could not resolve account name. Check parameters or try again."
+	messageInvalidKey         = "Invalid key"
+	messageRateLimited        = "Rate limit exceeded"
+	messageMissingParameter   = "Account number and bank code are required"
+)
+
+// Server is an http.Handler imitating paystack's /bank/resolve, /bank/resolve_bvn and /bank endpoints
+type Server struct {
+	fixtures        *Fixtures
+	apiKey          string
+	latency         time.Duration
+	errorRate       float64
+	errorStatusCode int
+
+	mu          sync.Mutex
+	failures    int
+	failureCode int
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAPIKey rejects requests that don't carry apiKey as their bearer token, any key is accepted without it
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithLatency delays every response by latency
+func WithLatency(latency time.Duration) Option {
+	return func(s *Server) {
+		s.latency = latency
+	}
+}
+
+// WithErrorRate fails a random fraction rate, between 0 and 1, of requests with statusCode
+func WithErrorRate(rate float64, statusCode int) Option {
+	return func(s *Server) {
+		s.errorRate = rate
+		s.errorStatusCode = statusCode
+	}
+}
+
+func NewServer(fixtures *Fixtures, opts ...Option) *Server {
+	s := &Server{fixtures: fixtures}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// FailNext fails the next n requests with statusCode
+func (s *Server) FailNext(n int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures = n
+	s.failureCode = statusCode
+}
+
+// injectedFailure returns the status code the current request should fail with, or 0
+func (s *Server) injectedFailure() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return s.failureCode
+	}
+
+	if s.errorRate > 0 && rand.Float64() < s.errorRate {
+		return s.errorStatusCode
+	}
+	return 0
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.latency > 0 {
+		select {
+		case <-time.After(s.latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	if s.apiKey != "" && r.Header.Get("Authorization") != "Bearer "+s.apiKey {
+		writeError(w, http.StatusUnauthorized, messageInvalidKey)
+		return
+	}
+
+	if statusCode := s.injectedFailure(); statusCode != 0 {
+		message := http.StatusText(statusCode)
+		if statusCode == http.StatusTooManyRequests {
+			message = messageRateLimited
+		}
+		writeError(w, statusCode, message)
+		return
+	}
+
+	path := strings.TrimRight(r.URL.Path, "/")
+	switch {
+	case path == resolveBankAccountPath:
+		s.resolveBankAccount(w, r)
+	case path == listBanksPath:
+		s.listBanks(w)
+	case strings.HasPrefix(path, resolveBVNPath):
+		s.resolveBVN(w, strings.TrimPrefix(path, resolveBVNPath))
+	default:
+		writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+}
+
+func (s *Server) resolveBankAccount(w http.ResponseWriter, r *http.Request) {
+	accountNumber := r.URL.Query().Get("account_number")
+	bankCode := r.URL.Query().Get("bank_code")
+	if accountNumber == "" || bankCode == "" {
+		writeError(w, http.StatusBadRequest, messageMissingParameter)
+		return
+	}
+
+	bank := s.fixtures.findBank(bankCode)
+	if bank == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Unknown bank code: %s", bankCode))
+		return
+	}
+
+	account := s.fixtures.findAccount(bankCode, accountNumber)
+	if account == nil {
+		writeError(w, http.StatusUnprocessableEntity, messageCouldNotResolve)
+		return
+	}
+
+	if account.StatusCode != 0 {
+		message := account.Message
+		if message == "" {
+			message = http.StatusText(account.StatusCode)
+		}
+		writeError(w, account.StatusCode, message)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &paystack.ResolveBankAccountResponse{
+		Status:  true,
+		Message: messageAccountResolved,
+		Data: &paystack.Data{
+			AccountNumber: account.AccountNumber,
+			AccountName:   account.AccountName,
+			BankID:        bank.ID,
+		},
+	})
+}
+
+func (s *Server) resolveBVN(w http.ResponseWriter, bvn string) {
+	identity := s.fixtures.findIdentity(bvn)
+	if identity == nil {
+		writeError(w, http.StatusBadRequest, messageCouldNotResolveBVN)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &paystack.ResolveBVNResponse{
+		Status:  true,
+		Message: messageBVNResolved,
+		Data: &paystack.BVNData{
+			FirstName:    identity.FirstName,
+			MiddleName:   identity.MiddleName,
+			LastName:     identity.LastName,
+			FormattedDob: identity.DateOfBirth,
+			Mobile:       identity.Mobile,
+			BVN:          identity.BVN,
+		},
+	})
+}
+
+func (s *Server) listBanks(w http.ResponseWriter) {
+	// every bank fits on the first page, so there is no next cursor
+	writeJSON(w, http.StatusOK, &struct {
+		Status  bool           `json:"status"`
+		Message string         `json:"message"`
+		Data    []*Bank        `json:"data"`
+		Meta    *paystack.Meta `json:"meta"`
+	}{
+		Status:  true,
+		Message: messageBanksRetrieved,
+		Data:    s.fixtures.Banks,
+		Meta:    &paystack.Meta{PerPage: len(s.fixtures.Banks)},
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, &paystack.ResolveBankAccountResponse{Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,177 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/providers/paystack"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func testFixtures() *Fixtures {
+	return &Fixtures{
+		Banks: []*Bank{
+			{ID: 20, Name: "Wema Bank", Code: "035", Active: true},
+			{ID: 9, Name: "Guaranty Trust Bank", Code: "058", Active: true},
+		},
+		Accounts: []*Account{
+			{AccountNumber: "7811035832", AccountName: "DANIEL OLUOJOMU", BankCode: "035"},
+			{AccountNumber: "0000005037", BankCode: "058", StatusCode: http.StatusServiceUnavailable},
+		},
+		Identities: []*Identity{
+			{BVN: "22212345678", FirstName: "DANIEL", LastName: "OLUOJOMU", DateOfBirth: "1995-06-14"},
+		},
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	server := httptest.NewServer(NewServer(testFixtures(), WithAPIKey("sk_test")))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		bankCode      string
+		accountNumber string
+		wantName      string
+		wantCause     error
+		wantKind      paystack.ErrorKind
+	}{
+		{
+			name:          "should_resolve_fixture_account",
+			apiKey:        "sk_test",
+			bankCode:      "035",
+			accountNumber: "7811035832",
+			wantName:      "DANIEL OLUOJOMU",
+		},
+		{
+			name:          "should_not_find_unknown_account",
+			apiKey:        "sk_test",
+			bankCode:      "058",
+			accountNumber: "7811035832",
+			wantCause:     app.ErrAccountNotFound,
+		},
+		{
+			name:          "should_reject_unknown_bank_code",
+			apiKey:        "sk_test",
+			bankCode:      "999",
+			accountNumber: "7811035832",
+			wantCause:     app.ErrUnknownBankCode,
+		},
+		{
+			name:          "should_fail_with_fixture_status_code",
+			apiKey:        "sk_test",
+			bankCode:      "058",
+			accountNumber: "0000005037",
+			wantCause:     app.ErrProviderUnavailable,
+		},
+		{
+			name:          "should_reject_wrong_api_key",
+			apiKey:        "sk_wrong",
+			bankCode:      "035",
+			accountNumber: "7811035832",
+			wantKind:      paystack.ErrorKindUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := paystack.NewAPIClient(tt.apiKey, paystack.WithBaseURL(server.URL))
+			account, err := client.ResolveAccount(context.Background(), tt.bankCode, tt.accountNumber)
+
+			switch {
+			case tt.wantCause != nil:
+				assert.Equal(t, tt.wantCause, errors.Cause(err))
+			case tt.wantKind != "":
+				if assert.IsType(t, &paystack.Error{}, err) {
+					assert.Equal(t, tt.wantKind, err.(*paystack.Error).Kind)
+				}
+			default:
+				if assert.NoError(t, err) {
+					assert.Equal(t, tt.wantName, account.AccountName)
+				}
+			}
+		})
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	fake := NewServer(testFixtures())
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := paystack.NewAPIClient("sk_test", paystack.WithBaseURL(server.URL))
+	fake.FailNext(1, http.StatusTooManyRequests)
+
+	_, err := client.ResolveBankAccount(context.Background(), &paystack.ResolveBankAccountRequest{AccountNumber: "7811035832", BankCode: "035"})
+	if assert.IsType(t, &paystack.Error{}, err) {
+		assert.Equal(t, paystack.ErrorKindRateLimited, err.(*paystack.Error).Kind)
+	}
+
+	_, err = client.ResolveBankAccount(context.Background(), &paystack.ResolveBankAccountRequest{AccountNumber: "7811035832", BankCode: "035"})
+	assert.NoError(t, err)
+}
+
+func TestLatency(t *testing.T) {
+	server := httptest.NewServer(NewServer(testFixtures(), WithLatency(50*time.Millisecond)))
+	defer server.Close()
+
+	client := paystack.NewAPIClient("sk_test", paystack.WithBaseURL(server.URL), paystack.WithTimeout(10*time.Millisecond))
+	_, err := client.ResolveAccount(context.Background(), "035", "7811035832")
+	assert.Equal(t, app.ErrProviderUnavailable, errors.Cause(err))
+}
+
+func TestResolveBVN(t *testing.T) {
+	server := httptest.NewServer(NewServer(testFixtures()))
+	defer server.Close()
+
+	client := paystack.NewAPIClient("sk_test", paystack.WithBaseURL(server.URL))
+
+	identity, err := client.ResolveBVN(context.Background(), "22212345678")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "DANIEL", identity.FirstName)
+		assert.Equal(t, "1995-06-14", identity.DateOfBirth.Format("2006-01-02"))
+	}
+
+	_, err = client.ResolveBVN(context.Background(), "22200000000")
+	assert.Equal(t, app.ErrBVNNotFound, errors.Cause(err))
+}
+
+func TestListBanks(t *testing.T) {
+	server := httptest.NewServer(NewServer(testFixtures()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + listBanksPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	body := &struct {
+		Status bool
+		Data   []*Bank
+	}{}
+	if assert.NoError(t, json.NewDecoder(resp.Body).Decode(body)) {
+		assert.True(t, body.Status)
+		assert.Len(t, body.Data, 2)
+		assert.Equal(t, "035", body.Data[0].Code)
+	}
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures("../../../config/paystack_fixtures.yml")
+	if assert.NoError(t, err) {
+		assert.NotNil(t, fixtures.findBank("035"))
+		assert.NotNil(t, fixtures.findAccount("035", "7811035832"))
+		assert.NotNil(t, fixtures.findIdentity("22212345678"))
+	}
+
+	_, err = LoadFixtures("missing.yml")
+	assert.Error(t, err)
+}
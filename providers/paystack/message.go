@@ -16,3 +16,44 @@ type Data struct {
 	AccountName   string `json:"account_name"`
 	BankID        int    `json:"bank_id"`
 }
+
+type ListBanksResponse struct {
+	Status  bool    `json:"status"`
+	Message string  `json:"message"`
+	Data    []*Bank `json:"data"`
+	Meta    *Meta   `json:"meta"`
+}
+
+type Bank struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Code     string `json:"code"`
+	LongCode string `json:"longcode"`
+	Type     string `json:"type"`
+	Country  string `json:"country"`
+	Currency string `json:"currency"`
+	Active   bool   `json:"active"`
+}
+
+// Meta holds the cursor of the next page of a list, Next is empty on the last page
+type Meta struct {
+	Next    string `json:"next"`
+	PerPage int    `json:"perPage"`
+}
+
+type ResolveBVNResponse struct {
+	Status  bool     `json:"status"`
+	Message string   `json:"message"`
+	Data    *BVNData `json:"data"`
+}
+
+type BVNData struct {
+	FirstName    string `json:"first_name"`
+	MiddleName   string `json:"middle_name"`
+	LastName     string `json:"last_name"`
+	Dob          string `json:"dob"`
+	FormattedDob string `json:"formatted_dob"`
+	Mobile       string `json:"mobile"`
+	BVN          string `json:"bvn"`
+}
@@ -4,60 +4,277 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
 	"github.com/pkg/errors"
-	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
 type APIClient struct {
-	apiKey string
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      *config.RetryConfig
 }
 
-func NewAPIClient(apiKey string) *APIClient {
-	return &APIClient{apiKey: apiKey}
+// Option configures an APIClient
+type Option func(*APIClient)
+
+// WithBaseURL sends requests to baseURL instead of https://api.paystack.co, e.g. a local sandbox
+func WithBaseURL(baseURL string) Option {
+	return func(a *APIClient) {
+		if baseURL != "" {
+			a.baseURL = strings.TrimRight(baseURL, "/")
+		}
+	}
+}
+
+// WithHTTPClient sends requests with client instead of http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(a *APIClient) {
+		if client != nil {
+			a.httpClient = client
+		}
+	}
+}
+
+// WithTimeout bounds every attempt of a request sent to paystack to timeout, so with retries a request can take longer
+func WithTimeout(timeout time.Duration) Option {
+	return func(a *APIClient) {
+		a.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(a *APIClient) {
+		a.userAgent = userAgent
+	}
+}
+
+// WithRetry retries failed requests according to policy, a nil policy sends every request once
+func WithRetry(policy *config.RetryConfig) Option {
+	return func(a *APIClient) {
+		a.retry = policy
+	}
+}
+
+// ConfigOptions returns the options set in cfg, it returns nil when cfg is nil
+func ConfigOptions(cfg *config.PaystackConfig) []Option {
+	if cfg == nil {
+		return nil
+	}
+
+	return []Option{
+		WithBaseURL(cfg.BaseURL),
+		WithTimeout(cfg.Timeout),
+		WithUserAgent(cfg.UserAgent),
+		WithRetry(cfg.Retry),
+	}
+}
+
+// NewAPIClient creates a paystack client, without options it sends every request
+// once to https://api.paystack.co with http.DefaultClient and no timeout
+func NewAPIClient(apiKey string, opts ...Option) *APIClient {
+	client := &APIClient{apiKey: apiKey, baseURL: defaultBaseURL, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.timeout > 0 {
+		// copy the client so we don't change the timeout for everyone else using it
+		httpClient := *client.httpClient
+		httpClient.Timeout = client.timeout
+		client.httpClient = &httpClient
+	}
+	return client
 }
 
+// ProviderName identifies paystack in resolved bank accounts
+const ProviderName = "paystack"
+
 const (
-	resolveBankAccountURL = "https://api.paystack.co/bank/resolve?account_number=%s&bank_code=%s"
-	authorizationHeader   = "Authorization"
+	defaultBaseURL         = "https://api.paystack.co"
+	resolveBankAccountPath = "/bank/resolve?account_number=%s&bank_code=%s"
+	listBanksPath          = "/bank?country=%s&use_cursor=true&perPage=%d"
+	resolveBVNPath         = "/bank/resolve_bvn/%s"
+	formattedDobLayout     = "2006-01-02"
+	listBanksPageSize      = 100
+	defaultCountry         = "nigeria"
+	authorizationHeader    = "Authorization"
+	userAgentHeader        = "User-Agent"
 )
 
+// ResolveBankAccount looks up the account on paystack, every failure paystack reports is returned as an *Error
 func (a *APIClient) ResolveBankAccount(ctx context.Context, acccount *ResolveBankAccountRequest) (*Data, error) {
-	url := fmt.Sprintf(resolveBankAccountURL, acccount.AccountNumber, acccount.BankCode)
+	url := a.baseURL + fmt.Sprintf(resolveBankAccountPath, acccount.AccountNumber, acccount.BankCode)
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	statusCode, body, err := a.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Add(authorizationHeader, "Bearer "+a.apiKey)
 
-	resp, err := http.DefaultClient.Do(request)
+	responseData := &ResolveBankAccountResponse{}
+	if err = json.Unmarshal(body, responseData); err != nil {
+		return nil, &Error{Kind: ErrorKindUnexpected, StatusCode: statusCode, Message: "invalid response body: " + err.Error()}
+	}
+
+	// paystack sometimes reports failures with a 200 and a false status
+	if !responseData.Status || responseData.Data == nil {
+		return nil, parseError(statusCode, body)
+	}
+	return responseData.Data, nil
+}
+
+// ListBanks returns every bank paystack lists for country, following the pages of the listing
+func (a *APIClient) ListBanks(ctx context.Context, country string) ([]*Bank, error) {
+	var banks []*Bank
+	url := a.baseURL + fmt.Sprintf(listBanksPath, country, listBanksPageSize)
+
+	for next := ""; ; {
+		pageURL := url
+		if next != "" {
+			pageURL += "&next=" + next
+		}
+
+		statusCode, body, err := a.get(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+
+		responseData := &ListBanksResponse{}
+		if err = json.Unmarshal(body, responseData); err != nil {
+			return nil, &Error{Kind: ErrorKindUnexpected, StatusCode: statusCode, Message: "invalid response body: " + err.Error()}
+		}
+
+		if !responseData.Status {
+			return nil, parseError(statusCode, body)
+		}
+
+		banks = append(banks, responseData.Data...)
+		if responseData.Meta == nil || responseData.Meta.Next == "" || responseData.Meta.Next == next {
+			return banks, nil
+		}
+		next = responseData.Meta.Next
+	}
+}
+
+// ResolveBVNData looks up the identity registered to a bank verification number on paystack
+func (a *APIClient) ResolveBVNData(ctx context.Context, bvn string) (*BVNData, error) {
+	statusCode, body, err := a.get(ctx, a.baseURL+fmt.Sprintf(resolveBVNPath, url.PathEscape(bvn)))
 	if err != nil {
 		return nil, err
 	}
 
+	responseData := &ResolveBVNResponse{}
+	if err = json.Unmarshal(body, responseData); err != nil {
+		return nil, &Error{Kind: ErrorKindUnexpected, StatusCode: statusCode, Message: "invalid response body: " + err.Error()}
+	}
+
+	if !responseData.Status || responseData.Data == nil {
+		return nil, parseError(statusCode, body)
+	}
+	return responseData.Data, nil
+}
+
+// get sends a GET request to url, every failure is returned as an *Error
+// except the context being done. The body is only returned for 200 responses
+func (a *APIClient) get(ctx context.Context, url string) (int, []byte, error) {
+	resp, err := a.do(ctx, func() (*http.Request, error) {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Add(authorizationHeader, "Bearer "+a.apiKey)
+		if a.userAgent != "" {
+			request.Header.Set(userAgentHeader, a.userAgent)
+		}
+		return request, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, nil, err
+		}
+		return 0, nil, &Error{Kind: ErrorKindUpstreamUnavailable, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, &Error{Kind: ErrorKindUpstreamUnavailable, StatusCode: resp.StatusCode, Message: err.Error()}
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("resolve bank account failed: status code %d", resp.StatusCode)
+		return 0, nil, parseError(resp.StatusCode, body)
 	}
+	return resp.StatusCode, body, nil
+}
 
-	responseData := &ResolveBankAccountResponse{}
-	err = getResponseData(resp.Body, responseData)
+// FetchBanks implements app.BankDirectory with the banks paystack lists for nigeria
+func (a *APIClient) FetchBanks(ctx context.Context) ([]*app.Bank, error) {
+	banks, err := a.ListBanks(ctx, defaultCountry)
 	if err != nil {
-		return nil, err
+		return nil, toAppError(err)
 	}
-	return responseData.Data, nil
+
+	directory := make([]*app.Bank, 0, len(banks))
+	for _, bank := range banks {
+		directory = append(directory, &app.Bank{
+			Code:     bank.Code,
+			Name:     bank.Name,
+			Slug:     bank.Slug,
+			LongCode: bank.LongCode,
+			Type:     bank.Type,
+			Country:  bank.Country,
+			Currency: bank.Currency,
+			Active:   bank.Active,
+		})
+	}
+	return directory, nil
 }
 
-func getResponseData(respBody io.ReadCloser, data interface{}) error {
-	responseData, err := ioutil.ReadAll(respBody)
+// ResolveBVN implements app.BVNResolver, BVNs paystack could not find have app.ErrBVNNotFound as their cause
+func (a *APIClient) ResolveBVN(ctx context.Context, bvn string) (*app.ResolvedBVN, error) {
+	data, err := a.ResolveBVNData(ctx, bvn)
+	if err != nil {
+		if e, ok := err.(*Error); ok && e.Kind == ErrorKindInvalidAccount {
+			return nil, errors.Wrap(app.ErrBVNNotFound, e.Error())
+		}
+		return nil, toAppError(err)
+	}
+
+	dateOfBirth, err := time.Parse(formattedDobLayout, data.FormattedDob)
 	if err != nil {
-		return err
+		return nil, &Error{Kind: ErrorKindUnexpected, StatusCode: http.StatusOK, Message: "invalid date of birth: " + data.FormattedDob}
 	}
 
-	if err = json.Unmarshal(responseData, data); err != nil {
-		return err
+	return &app.ResolvedBVN{
+		BVN:         data.BVN,
+		FirstName:   data.FirstName,
+		MiddleName:  data.MiddleName,
+		LastName:    data.LastName,
+		DateOfBirth: dateOfBirth,
+		Provider:    ProviderName,
+	}, nil
+}
+
+// ResolveAccount implements app.BankAccountResolver
+func (a *APIClient) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	data, err := a.ResolveBankAccount(ctx, &ResolveBankAccountRequest{AccountNumber: accountNumber, BankCode: bankCode})
+	if err != nil {
+		return nil, toAppError(err)
 	}
 
-	return nil
+	return &app.ResolvedBankAccount{
+		AccountNumber: data.AccountNumber,
+		AccountName:   data.AccountName,
+		BankCode:      bankCode,
+		Provider:      ProviderName,
+	}, nil
 }
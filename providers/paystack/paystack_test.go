@@ -0,0 +1,346 @@
+package paystack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const resolvedAccount = `{"status":true,"message":"Account number resolved","data":{"account_number":"7811035835","account_name":"DANIEL OLUOJOMU","bank_id":9}}`
+
+// newFlakyServer fails the first failures requests with statusCode before resolving the account
+func newFlakyServer(failures int32, statusCode int, retryAfter string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			if retryAfter != "" {
+				w.Header().Set(retryAfterHeader, retryAfter)
+			}
+			w.WriteHeader(statusCode)
+			return
+		}
+		_, _ = w.Write([]byte(resolvedAccount))
+	}))
+	return server, &calls
+}
+
+func newTestClient(baseURL string, retry *config.RetryConfig) *APIClient {
+	return NewAPIClient("sk_test", WithBaseURL(baseURL), WithRetry(retry))
+}
+
+func testRetryConfig() *config.RetryConfig {
+	return &config.RetryConfig{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           10 * time.Millisecond,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable},
+	}
+}
+
+func TestResolveBankAccountRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int32
+		statusCode int
+		retryAfter string
+		retry      *config.RetryConfig
+		wantCalls  int32
+		wantErr    bool
+		wantKind   ErrorKind
+	}{
+		{
+			name:       "should_succeed_after_transient_failures",
+			failures:   2,
+			statusCode: http.StatusServiceUnavailable,
+			retry:      testRetryConfig(),
+			wantCalls:  3,
+		},
+		{
+			name:       "should_honor_retry_after_on_rate_limit",
+			failures:   1,
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "0",
+			retry:      testRetryConfig(),
+			wantCalls:  2,
+		},
+		{
+			name:       "should_give_up_when_retry_after_exceeds_max_backoff",
+			failures:   1,
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: "120",
+			retry:      testRetryConfig(),
+			wantCalls:  1,
+			wantErr:    true,
+			wantKind:   ErrorKindRateLimited,
+		},
+		{
+			name:       "should_give_up_after_max_attempts",
+			failures:   5,
+			statusCode: http.StatusBadGateway,
+			retry:      testRetryConfig(),
+			wantCalls:  3,
+			wantErr:    true,
+			wantKind:   ErrorKindUpstreamUnavailable,
+		},
+		{
+			name:       "should_not_retry_other_status_codes",
+			failures:   1,
+			statusCode: http.StatusBadRequest,
+			retry:      testRetryConfig(),
+			wantCalls:  1,
+			wantErr:    true,
+			wantKind:   ErrorKindInvalidAccount,
+		},
+		{
+			name:       "should_send_once_without_retry_config",
+			failures:   1,
+			statusCode: http.StatusServiceUnavailable,
+			wantCalls:  1,
+			wantErr:    true,
+			wantKind:   ErrorKindUpstreamUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newFlakyServer(tt.failures, tt.statusCode, tt.retryAfter)
+			defer server.Close()
+
+			client := newTestClient(server.URL, tt.retry)
+			data, err := client.ResolveBankAccount(context.Background(), &ResolveBankAccountRequest{AccountNumber: "7811035835", BankCode: "035"})
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(calls))
+
+			if tt.wantErr {
+				if assert.IsType(t, &Error{}, err) {
+					assert.Equal(t, tt.wantKind, err.(*Error).Kind)
+				}
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, "DANIEL OLUOJOMU", data.AccountName)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := &config.RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, backoff(policy, 1))
+	assert.Equal(t, 200*time.Millisecond, backoff(policy, 2))
+	assert.Equal(t, 400*time.Millisecond, backoff(policy, 3))
+	assert.Equal(t, time.Second, backoff(policy, 10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		wait := backoff(policy, 1)
+		assert.True(t, wait >= 50*time.Millisecond && wait <= 150*time.Millisecond, "wait %v", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("3")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.True(t, wait > 59*time.Minute)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestResolveBankAccountErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantKind   ErrorKind
+		wantCause  error
+	}{
+		{
+			name:       "should_parse_invalid_account",
+			statusCode: http.StatusUnprocessableEntity,
+			body:       `{"status":false,"message":"Could not resolve account name. Check parameters or try again."}`,
+			wantKind:   ErrorKindInvalidAccount,
+			wantCause:  app.ErrAccountNotFound,
+		},
+		{
+			name:       "should_parse_unknown_bank_code",
+			statusCode: http.StatusBadRequest,
+			body:       `{"status":false,"message":"Unknown bank code: 999"}`,
+			wantKind:   ErrorKindUnknownBankCode,
+			wantCause:  app.ErrUnknownBankCode,
+		},
+		{
+			name:       "should_parse_unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"status":false,"message":"Invalid key"}`,
+			wantKind:   ErrorKindUnauthorized,
+		},
+		{
+			name:       "should_parse_rate_limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       `{"status":false,"message":"Too many requests"}`,
+			wantKind:   ErrorKindRateLimited,
+			wantCause:  app.ErrProviderUnavailable,
+		},
+		{
+			name:       "should_parse_upstream_unavailable_without_json",
+			statusCode: http.StatusBadGateway,
+			body:       `<html>bad gateway</html>`,
+			wantKind:   ErrorKindUpstreamUnavailable,
+			wantCause:  app.ErrProviderUnavailable,
+		},
+		{
+			name:       "should_parse_false_status_on_ok",
+			statusCode: http.StatusOK,
+			body:       `{"status":false,"message":"Could not resolve account name. Check parameters or try again."}`,
+			wantKind:   ErrorKindInvalidAccount,
+			wantCause:  app.ErrAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(server.URL, nil)
+			_, err := client.ResolveBankAccount(context.Background(), &ResolveBankAccountRequest{AccountNumber: "7811035835", BankCode: "035"})
+			if assert.IsType(t, &Error{}, err) {
+				assert.Equal(t, tt.wantKind, err.(*Error).Kind)
+			}
+
+			_, err = client.ResolveAccount(context.Background(), "035", "7811035835")
+			if tt.wantCause != nil {
+				assert.Equal(t, tt.wantCause, errors.Cause(err))
+			} else {
+				assert.IsType(t, &Error{}, err)
+			}
+		})
+	}
+}
+
+func TestAPIClientOptions(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get(userAgentHeader)
+		if r.URL.Query().Get("account_number") == "slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(resolvedAccount))
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{}
+	client := NewAPIClient("sk_test",
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(httpClient),
+		WithTimeout(10*time.Millisecond),
+		WithUserAgent("buycoin-test"),
+	)
+
+	data, err := client.ResolveBankAccount(context.Background(), &ResolveBankAccountRequest{AccountNumber: "7811035835", BankCode: "035"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "DANIEL OLUOJOMU", data.AccountName)
+	}
+	assert.Equal(t, "buycoin-test", userAgent)
+
+	_, err = client.ResolveBankAccount(context.Background(), &ResolveBankAccountRequest{AccountNumber: "slow", BankCode: "035"})
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, ErrorKindUpstreamUnavailable, err.(*Error).Kind)
+	}
+
+	// the timeout is set on a copy of the client we were given
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+}
+
+func TestConfigOptions(t *testing.T) {
+	assert.Nil(t, ConfigOptions(nil))
+
+	client := NewAPIClient("sk_test", ConfigOptions(&config.PaystackConfig{
+		BaseURL:   "http://localhost:8090",
+		Timeout:   time.Second,
+		UserAgent: "buycoin-test",
+		Retry:     testRetryConfig(),
+	})...)
+	assert.Equal(t, "http://localhost:8090", client.baseURL)
+	assert.Equal(t, time.Second, client.httpClient.Timeout)
+	assert.Equal(t, "buycoin-test", client.userAgent)
+	assert.NotNil(t, client.retry)
+
+	client = NewAPIClient("sk_test", ConfigOptions(&config.PaystackConfig{})...)
+	assert.Equal(t, defaultBaseURL, client.baseURL)
+	assert.Equal(t, http.DefaultClient, client.httpClient)
+}
+
+func TestListBanks(t *testing.T) {
+	pages := map[string]string{
+		"":         `{"status":true,"message":"Banks retrieved","data":[{"name":"Access Bank","code":"044","active":true}],"meta":{"next":"YmFuazoy","perPage":1}}`,
+		"YmFuazoy": `{"status":true,"message":"Banks retrieved","data":[{"name":"Wema Bank","code":"035","active":true}],"meta":{"next":null,"perPage":1}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "nigeria", r.URL.Query().Get("country"))
+		page, ok := pages[r.URL.Query().Get("next")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, nil)
+	banks, err := client.FetchBanks(context.Background())
+	if assert.NoError(t, err) && assert.Len(t, banks, 2) {
+		assert.Equal(t, "044", banks[0].Code)
+		assert.Equal(t, "Wema Bank", banks[1].Name)
+		assert.True(t, banks[1].Active)
+	}
+}
+
+func TestResolveBVN(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bank/resolve_bvn/22212345678":
+			_, _ = w.Write([]byte(`{"status":true,"message":"BVN resolved","data":{"first_name":"DANIEL","last_name":"OLUOJOMU","dob":"14-Jun-95","formatted_dob":"1995-06-14","mobile":"08012345678","bvn":"22212345678"}}`))
+		case "/bank/resolve_bvn/22200000000":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":false,"message":"Unable to resolve BVN"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, nil)
+
+	identity, err := client.ResolveBVN(context.Background(), "22212345678")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "DANIEL", identity.FirstName)
+		assert.Equal(t, "OLUOJOMU", identity.LastName)
+		assert.Equal(t, time.Date(1995, time.June, 14, 0, 0, 0, 0, time.UTC), identity.DateOfBirth)
+		assert.Equal(t, ProviderName, identity.Provider)
+	}
+
+	_, err = client.ResolveBVN(context.Background(), "22200000000")
+	assert.Equal(t, app.ErrBVNNotFound, errors.Cause(err))
+
+	_, err = client.ResolveBVN(context.Background(), "22299999999")
+	assert.Equal(t, app.ErrProviderUnavailable, errors.Cause(err))
+}
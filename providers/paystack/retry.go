@@ -0,0 +1,122 @@
+package paystack
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+)
+
+const retryAfterHeader = "Retry-After"
+
+// do sends the request built by newRequest, retrying transport errors and retryable status codes
+// as allowed by the client's retry policy. A nil policy sends the request once.
+// The last response is returned as is when attempts run out, so callers handle its status code
+func (a *APIClient) do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	attempts := 1
+	if a.retry != nil && a.retry.MaxAttempts > 1 {
+		attempts = a.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		request, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := a.httpClient.Do(request)
+		if attempt == attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			wait = backoff(a.retry, attempt)
+		case a.isRetryable(resp.StatusCode):
+			wait = backoff(a.retry, attempt)
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get(retryAfterHeader)); ok {
+				// waiting longer than we would ever back off for is not worth it
+				if a.retry.MaxBackoff > 0 && retryAfter > a.retry.MaxBackoff {
+					return resp, nil
+				}
+				wait = retryAfter
+			}
+			drain(resp.Body)
+		default:
+			return resp, nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (a *APIClient) isRetryable(statusCode int) bool {
+	for _, code := range a.retry.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait after the given attempt: the initial backoff doubled
+// for every previous attempt, capped at the max backoff and shifted by a random jitter
+func backoff(policy *config.RetryConfig, attempt int) time.Duration {
+	wait := policy.InitialBackoff
+	for i := 1; i < attempt && (policy.MaxBackoff == 0 || wait < policy.MaxBackoff); i++ {
+		wait *= 2
+	}
+
+	if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+		wait = policy.MaxBackoff
+	}
+
+	if policy.Jitter > 0 {
+		// shift by a random amount in [-jitter, +jitter) of the wait
+		wait += time.Duration((rand.Float64()*2 - 1) * policy.Jitter * float64(wait))
+	}
+	return wait
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	wait := time.Until(date)
+	if wait < 0 {
+		wait = 0
+	}
+	return wait, true
+}
+
+// drain reads and closes a response body we're discarding so its connection can be reused
+func drain(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, body)
+	_ = body.Close()
+}
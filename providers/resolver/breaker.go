@@ -0,0 +1,156 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned without calling the provider while the circuit breaker is open,
+// its cause is app.ErrProviderUnavailable
+var ErrCircuitOpen = errors.Wrap(app.ErrProviderUnavailable, "circuit breaker open")
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+const (
+	defaultFailureThreshold    = 5
+	defaultOpenTimeout         = 30 * time.Second
+	defaultHalfOpenMaxRequests = 1
+)
+
+// CircuitBreaker stops calling a provider that keeps failing. After FailureThreshold consecutive
+// failures the circuit opens and every call fails fast with ErrCircuitOpen. Once OpenTimeout has
+// passed the circuit is half open and lets HalfOpenMaxRequests trial calls through: a successful
+// one closes the circuit and a failed one opens it again.
+// Only errors caused by app.ErrProviderUnavailable count as failures, an account that doesn't
+// exist means the provider is working fine
+type CircuitBreaker struct {
+	resolver            app.BankAccountResolver
+	failureThreshold    int
+	openTimeout         time.Duration
+	halfOpenMaxRequests int
+	now                 func() time.Time
+
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	halfOpenRequests int
+}
+
+// BreakerStats is a snapshot of a CircuitBreaker
+type BreakerStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+func NewCircuitBreaker(resolver app.BankAccountResolver, cfg *config.CircuitBreakerConfig) *CircuitBreaker {
+	b := &CircuitBreaker{
+		resolver:            resolver,
+		failureThreshold:    defaultFailureThreshold,
+		openTimeout:         defaultOpenTimeout,
+		halfOpenMaxRequests: defaultHalfOpenMaxRequests,
+		now:                 time.Now,
+		state:               StateClosed,
+	}
+
+	if cfg != nil {
+		if cfg.FailureThreshold > 0 {
+			b.failureThreshold = cfg.FailureThreshold
+		}
+		if cfg.OpenTimeout > 0 {
+			b.openTimeout = cfg.OpenTimeout
+		}
+		if cfg.HalfOpenMaxRequests > 0 {
+			b.halfOpenMaxRequests = cfg.HalfOpenMaxRequests
+		}
+	}
+	return b
+}
+
+// ResolveAccount implements app.BankAccountResolver
+func (b *CircuitBreaker) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	account, err := b.resolver.ResolveAccount(ctx, bankCode, accountNumber)
+	b.record(ctx, err)
+	return account, err
+}
+
+// allow reports whether a call may go through, moving an open circuit to half open once it timed out
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		b.state = StateHalfOpen
+		b.halfOpenRequests = 0
+	}
+
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.halfOpenRequests >= b.halfOpenMaxRequests {
+			return false
+		}
+		b.halfOpenRequests++
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// a caller giving up tells us nothing about the provider, just free the trial slot
+	if err != nil && ctx.Err() != nil {
+		if b.state == StateHalfOpen && b.halfOpenRequests > 0 {
+			b.halfOpenRequests--
+		}
+		return
+	}
+
+	if !isUnavailable(err) {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Stats returns the current state of the circuit breaker
+func (b *CircuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		state = StateHalfOpen
+	}
+
+	stats := BreakerStats{State: state, ConsecutiveFailures: b.failures}
+	if state != StateClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
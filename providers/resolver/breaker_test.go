@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	stub := &stubResolver{name: "paystack", err: errUnavailable}
+	breaker := NewCircuitBreaker(stub, &config.CircuitBreakerConfig{
+		FailureThreshold:    2,
+		OpenTimeout:         time.Minute,
+		HalfOpenMaxRequests: 1,
+	})
+
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	resolve := func() error {
+		_, err := breaker.ResolveAccount(context.Background(), "035", "7811035835")
+		return err
+	}
+
+	// failures below the threshold go through to the provider
+	assert.Equal(t, errUnavailable, resolve())
+	assert.Equal(t, StateClosed, breaker.Stats().State)
+
+	// reaching the threshold opens the circuit
+	assert.Equal(t, errUnavailable, resolve())
+	assert.Equal(t, StateOpen, breaker.Stats().State)
+
+	// an open circuit fails fast without calling the provider
+	assert.Equal(t, ErrCircuitOpen, resolve())
+	assert.Equal(t, 2, stub.calls)
+
+	// after the timeout a failed trial opens the circuit again
+	now = now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, breaker.Stats().State)
+	assert.Equal(t, errUnavailable, resolve())
+	assert.Equal(t, StateOpen, breaker.Stats().State)
+	assert.Equal(t, 3, stub.calls)
+
+	// and a successful trial closes it
+	now = now.Add(time.Minute)
+	stub.err = nil
+	assert.NoError(t, resolve())
+	assert.Equal(t, StateClosed, breaker.Stats().State)
+	assert.Equal(t, 0, breaker.Stats().ConsecutiveFailures)
+}
+
+func TestCircuitBreakerIgnoresAccountErrors(t *testing.T) {
+	stub := &stubResolver{name: "paystack", err: errNotFound}
+	breaker := NewCircuitBreaker(stub, &config.CircuitBreakerConfig{FailureThreshold: 1})
+
+	for i := 0; i < 3; i++ {
+		_, err := breaker.ResolveAccount(context.Background(), "035", "7811035835")
+		assert.Equal(t, errNotFound, err)
+	}
+	assert.Equal(t, StateClosed, breaker.Stats().State)
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestCircuitBreakerLimitsHalfOpenRequests(t *testing.T) {
+	breaker := NewCircuitBreaker(&stubResolver{name: "paystack"}, &config.CircuitBreakerConfig{HalfOpenMaxRequests: 1})
+	breaker.state = StateHalfOpen
+
+	assert.True(t, breaker.allow())
+	assert.False(t, breaker.allow())
+}
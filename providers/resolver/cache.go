@@ -0,0 +1,148 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCacheTTL           = 24 * time.Hour
+	defaultCachePurgeInterval = time.Hour
+)
+
+// CachingResolver answers from a cache before asking its resolver, so the same account isn't paid for twice.
+// Accounts the resolver could not find are cached too, for a shorter time, and fail with app.ErrAccountNotFound.
+// Cache errors are logged and treated as misses since the resolver can still answer
+type CachingResolver struct {
+	resolver    app.BankAccountResolver
+	cache       app.BankAccountCache
+	ttl         time.Duration
+	negativeTTL time.Duration
+	logger      *log.Entry
+	now         func() time.Time
+
+	hits         uint64
+	negativeHits uint64
+	misses       uint64
+}
+
+// CacheStats counts how often a CachingResolver answered from its cache
+type CacheStats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+}
+
+func NewCachingResolver(resolver app.BankAccountResolver, cache app.BankAccountCache, cfg *config.AccountCacheConfig, logger *log.Entry) *CachingResolver {
+	c := &CachingResolver{
+		resolver: resolver,
+		cache:    cache,
+		ttl:      defaultCacheTTL,
+		logger:   logger,
+		now:      time.Now,
+	}
+
+	if cfg != nil {
+		if cfg.TTL > 0 {
+			c.ttl = cfg.TTL
+		}
+		c.negativeTTL = cfg.NegativeTTL
+	}
+	return c
+}
+
+// ResolveAccount implements app.BankAccountResolver
+func (c *CachingResolver) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	bankCode, accountNumber = strings.TrimSpace(bankCode), strings.TrimSpace(accountNumber)
+
+	cached, err := c.cache.FindCachedBankAccount(ctx, bankCode, accountNumber)
+	if err != nil {
+		c.logger.WithError(err).Warn("failed to read bank account cache")
+	}
+
+	if cached != nil && cached.ExpiresAt.After(c.now()) {
+		if cached.NotFound {
+			atomic.AddUint64(&c.negativeHits, 1)
+			return nil, errors.Wrap(app.ErrAccountNotFound, "cached")
+		}
+
+		atomic.AddUint64(&c.hits, 1)
+		return &app.ResolvedBankAccount{
+			AccountNumber: cached.AccountNumber,
+			AccountName:   cached.AccountName,
+			BankCode:      cached.BankCode,
+			Provider:      cached.Provider,
+		}, nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	account, err := c.resolver.ResolveAccount(ctx, bankCode, accountNumber)
+	switch {
+	case err == nil && account.Disagreement == nil:
+		// accounts providers disagree on are asked again so a review isn't skipped
+		c.save(ctx, &app.CachedBankAccount{
+			BankCode:      bankCode,
+			AccountNumber: accountNumber,
+			AccountName:   account.AccountName,
+			Provider:      account.Provider,
+		}, c.ttl)
+	case errors.Cause(err) == app.ErrAccountNotFound && c.negativeTTL > 0:
+		c.save(ctx, &app.CachedBankAccount{
+			BankCode:      bankCode,
+			AccountNumber: accountNumber,
+			NotFound:      true,
+		}, c.negativeTTL)
+	}
+	return account, err
+}
+
+func (c *CachingResolver) save(ctx context.Context, account *app.CachedBankAccount, ttl time.Duration) {
+	now := c.now()
+	account.CreatedAt = now
+	account.ExpiresAt = now.Add(ttl)
+
+	if err := c.cache.SaveCachedBankAccount(ctx, account); err != nil {
+		c.logger.WithError(err).Warn("failed to write bank account cache")
+	}
+}
+
+// Stats returns the cache hits and misses counted so far
+func (c *CachingResolver) Stats() CacheStats {
+	return CacheStats{
+		Hits:         atomic.LoadUint64(&c.hits),
+		NegativeHits: atomic.LoadUint64(&c.negativeHits),
+		Misses:       atomic.LoadUint64(&c.misses),
+	}
+}
+
+// PurgeExpired deletes the accounts in cache that have expired every interval, an hour when interval isn't
+// positive, until ctx is done
+func PurgeExpired(ctx context.Context, cache app.PurgeableBankAccountCache, interval time.Duration, logger *log.Entry) {
+	if interval <= 0 {
+		interval = defaultCachePurgeInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := cache.DeleteExpiredCachedBankAccounts(ctx, time.Now())
+			if err != nil {
+				logger.WithError(err).Error("failed to purge expired bank accounts")
+				continue
+			}
+			logger.WithField("deleted", deleted).Debug("purged expired bank accounts")
+		}
+	}
+}
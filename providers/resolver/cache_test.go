@@ -0,0 +1,174 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCache fails every read and write
+type failingCache struct{}
+
+func (failingCache) FindCachedBankAccount(ctx context.Context, bankCode string, accountNumber string) (*app.CachedBankAccount, error) {
+	return nil, errors.New("cache unavailable")
+}
+
+func (failingCache) SaveCachedBankAccount(ctx context.Context, account *app.CachedBankAccount) error {
+	return errors.New("cache unavailable")
+}
+
+// purgingCache reports every purge on purged
+type purgingCache struct {
+	failingCache
+	purged chan time.Time
+}
+
+func (p *purgingCache) DeleteExpiredCachedBankAccounts(ctx context.Context, now time.Time) (int64, error) {
+	p.purged <- now
+	return 1, nil
+}
+
+func newTestCachingResolver(stub *stubResolver, cfg *config.AccountCacheConfig) (*CachingResolver, *time.Time) {
+	now := time.Now()
+	cache := NewMemoryCache(10)
+	cache.now = func() time.Time { return now }
+
+	c := NewCachingResolver(stub, cache, cfg, log.WithField("test", "cache"))
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCachingResolver(t *testing.T) {
+	stub := &stubResolver{name: "paystack"}
+	c, now := newTestCachingResolver(stub, &config.AccountCacheConfig{TTL: time.Hour})
+
+	// the first lookup misses and asks the provider
+	account, err := c.ResolveAccount(context.Background(), "035", "7811035835")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "paystack", account.AccountName)
+	}
+	assert.Equal(t, 1, stub.calls)
+
+	// the second one is answered from the cache
+	account, err = c.ResolveAccount(context.Background(), "035", " 7811035835 ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "paystack", account.AccountName)
+		assert.Equal(t, "paystack", account.Provider)
+	}
+	assert.Equal(t, 1, stub.calls)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, c.Stats())
+
+	// expired accounts are resolved again
+	*now = now.Add(time.Hour)
+	_, err = c.ResolveAccount(context.Background(), "035", "7811035835")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stub.calls)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, c.Stats())
+}
+
+func TestCachingResolverNegativeCaching(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		negativeTTL time.Duration
+		wantCalls   int
+		wantStats   CacheStats
+	}{
+		{
+			name:        "should_cache_missing_accounts",
+			err:         errNotFound,
+			negativeTTL: time.Minute,
+			wantCalls:   1,
+			wantStats:   CacheStats{NegativeHits: 1, Misses: 1},
+		},
+		{
+			name:      "should_not_cache_missing_accounts_without_negative_ttl",
+			err:       errNotFound,
+			wantCalls: 2,
+			wantStats: CacheStats{Misses: 2},
+		},
+		{
+			name:        "should_not_cache_provider_failures",
+			err:         errUnavailable,
+			negativeTTL: time.Minute,
+			wantCalls:   2,
+			wantStats:   CacheStats{Misses: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubResolver{name: "paystack", err: tt.err}
+			c, _ := newTestCachingResolver(stub, &config.AccountCacheConfig{NegativeTTL: tt.negativeTTL})
+
+			for i := 0; i < 2; i++ {
+				_, err := c.ResolveAccount(context.Background(), "035", "7811035835")
+				assert.Equal(t, errors.Cause(tt.err), errors.Cause(err))
+			}
+			assert.Equal(t, tt.wantCalls, stub.calls)
+			assert.Equal(t, tt.wantStats, c.Stats())
+		})
+	}
+}
+
+func TestCachingResolverSkipsDisagreements(t *testing.T) {
+	composite := NewComposite(providers(&stubResolver{name: "paystack"}, &stubResolver{name: "flutterwave"}), log.WithField("test", "cache")).
+		WithConsensus(func(a, b string) bool { return a == b })
+	c := NewCachingResolver(composite, NewMemoryCache(10), nil, log.WithField("test", "cache"))
+
+	for i := 0; i < 2; i++ {
+		account, err := c.ResolveAccount(context.Background(), "035", "7811035835")
+		if assert.NoError(t, err) {
+			assert.NotNil(t, account.Disagreement)
+		}
+	}
+	assert.Equal(t, CacheStats{Misses: 2}, c.Stats())
+}
+
+func TestCachingResolverIgnoresCacheErrors(t *testing.T) {
+	stub := &stubResolver{name: "paystack"}
+	c := NewCachingResolver(stub, failingCache{}, nil, log.WithField("test", "cache"))
+
+	account, err := c.ResolveAccount(context.Background(), "035", "7811035835")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "paystack", account.AccountName)
+	}
+	assert.Equal(t, 1, stub.calls)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	cache := &purgingCache{purged: make(chan time.Time)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		PurgeExpired(ctx, cache, time.Millisecond, log.WithField("test", "purge"))
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-cache.purged:
+		case <-time.After(time.Second):
+			t.Fatal("expired accounts weren't purged")
+		}
+	}
+
+	cancel()
+	for {
+		select {
+		case <-done:
+			return
+		case <-cache.purged:
+			// ticks may still fire along with the cancellation
+		case <-time.After(time.Second):
+			t.Fatal("PurgeExpired didn't return after the context was done")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package resolver
+
+import (
+	"context"
+	"sync"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Provider is a named BankAccountResolver
+type Provider struct {
+	Name     string
+	Resolver app.BankAccountResolver
+}
+
+// Composite resolves bank accounts with several providers in order of priority, moving on to
+// the next provider whenever one fails with app.ErrProviderUnavailable as the cause
+type Composite struct {
+	providers []Provider
+	sameName  func(a, b string) bool
+	logger    *log.Entry
+}
+
+func NewComposite(providers []Provider, logger *log.Entry) *Composite {
+	return &Composite{providers: providers, logger: logger}
+}
+
+// WithConsensus makes the composite ask its first two providers at once. When both resolve the
+// account and sameName reports different names, the result carries the other provider's answer
+// in its Disagreement field
+func (c *Composite) WithConsensus(sameName func(a, b string) bool) *Composite {
+	c.sameName = sameName
+	return c
+}
+
+type result struct {
+	account *app.ResolvedBankAccount
+	err     error
+}
+
+// ResolveAccount implements app.BankAccountResolver
+func (c *Composite) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	if c.sameName == nil || len(c.providers) < 2 {
+		return c.failover(ctx, c.providers, bankCode, accountNumber)
+	}
+
+	var results [2]result
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			account, err := c.providers[i].Resolver.ResolveAccount(ctx, bankCode, accountNumber)
+			results[i] = result{account: account, err: err}
+		}(i)
+	}
+	wg.Wait()
+
+	primary, secondary := results[0], results[1]
+	switch {
+	case primary.err == nil && secondary.err == nil:
+		if !c.sameName(primary.account.AccountName, secondary.account.AccountName) {
+			c.logger.WithFields(log.Fields{
+				"bank_code":          bankCode,
+				"account_number":     accountNumber,
+				"provider":           primary.account.Provider,
+				"account_name":       primary.account.AccountName,
+				"other_provider":     secondary.account.Provider,
+				"other_account_name": secondary.account.AccountName,
+			}).Warn("providers disagree on account name")
+			primary.account.Disagreement = secondary.account
+		}
+		return primary.account, nil
+	case primary.err == nil:
+		c.logProviderError(c.providers[1], secondary.err)
+		return primary.account, nil
+	case !isUnavailable(primary.err):
+		return nil, primary.err
+	case secondary.err == nil:
+		c.logProviderError(c.providers[0], primary.err)
+		return secondary.account, nil
+	case !isUnavailable(secondary.err):
+		c.logProviderError(c.providers[0], primary.err)
+		return nil, secondary.err
+	}
+
+	c.logProviderError(c.providers[0], primary.err)
+	c.logProviderError(c.providers[1], secondary.err)
+	return c.failover(ctx, c.providers[2:], bankCode, accountNumber)
+}
+
+func (c *Composite) failover(ctx context.Context, providers []Provider, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	for _, provider := range providers {
+		account, err := provider.Resolver.ResolveAccount(ctx, bankCode, accountNumber)
+		if err == nil {
+			return account, nil
+		}
+
+		if !isUnavailable(err) {
+			return nil, err
+		}
+		c.logProviderError(provider, err)
+	}
+
+	return nil, errors.Wrap(app.ErrProviderUnavailable, "no bank account provider available")
+}
+
+func (c *Composite) logProviderError(provider Provider, err error) {
+	c.logger.WithError(err).WithField("provider", provider.Name).Warn("bank account provider failed")
+}
+
+func isUnavailable(err error) bool {
+	return errors.Cause(err) == app.ErrProviderUnavailable
+}
@@ -0,0 +1,152 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubResolver answers with a fixed account name or error and counts its calls
+type stubResolver struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (s *stubResolver) ResolveAccount(ctx context.Context, bankCode string, accountNumber string) (*app.ResolvedBankAccount, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &app.ResolvedBankAccount{AccountNumber: accountNumber, BankCode: bankCode, AccountName: s.name, Provider: s.name}, nil
+}
+
+var (
+	errUnavailable = errors.Wrap(app.ErrProviderUnavailable, "status code 503")
+	errNotFound    = errors.Wrap(app.ErrAccountNotFound, "could not resolve account")
+)
+
+func providers(resolvers ...*stubResolver) []Provider {
+	var p []Provider
+	for _, r := range resolvers {
+		p = append(p, Provider{Name: r.name, Resolver: r})
+	}
+	return p
+}
+
+func TestCompositeFailover(t *testing.T) {
+	tests := []struct {
+		name         string
+		resolvers    []*stubResolver
+		wantProvider string
+		wantCause    error
+		wantCalls    []int
+	}{
+		{
+			name:         "should_use_first_provider",
+			resolvers:    []*stubResolver{{name: "first"}, {name: "second"}},
+			wantProvider: "first",
+			wantCalls:    []int{1, 0},
+		},
+		{
+			name:         "should_fail_over_when_unavailable",
+			resolvers:    []*stubResolver{{name: "first", err: errUnavailable}, {name: "second"}},
+			wantProvider: "second",
+			wantCalls:    []int{1, 1},
+		},
+		{
+			name:      "should_not_fail_over_when_account_not_found",
+			resolvers: []*stubResolver{{name: "first", err: errNotFound}, {name: "second"}},
+			wantCause: app.ErrAccountNotFound,
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "should_error_when_every_provider_is_unavailable",
+			resolvers: []*stubResolver{{name: "first", err: errUnavailable}, {name: "second", err: errUnavailable}},
+			wantCause: app.ErrProviderUnavailable,
+			wantCalls: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composite := NewComposite(providers(tt.resolvers...), log.WithField("test", tt.name))
+
+			account, err := composite.ResolveAccount(context.Background(), "035", "7811035835")
+			if tt.wantCause != nil {
+				assert.Equal(t, tt.wantCause, errors.Cause(err))
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantProvider, account.Provider)
+			}
+
+			for i, r := range tt.resolvers {
+				assert.Equal(t, tt.wantCalls[i], r.calls, "calls to %s", r.name)
+			}
+		})
+	}
+}
+
+func TestCompositeConsensus(t *testing.T) {
+	sameName := func(a, b string) bool { return a == b }
+
+	tests := []struct {
+		name             string
+		resolvers        []*stubResolver
+		wantProvider     string
+		wantDisagreement string
+		wantCause        error
+	}{
+		{
+			name:         "should_not_flag_agreeing_providers",
+			resolvers:    []*stubResolver{{name: "same"}, {name: "same"}},
+			wantProvider: "same",
+		},
+		{
+			name:             "should_flag_disagreeing_providers",
+			resolvers:        []*stubResolver{{name: "first"}, {name: "second"}},
+			wantProvider:     "first",
+			wantDisagreement: "second",
+		},
+		{
+			name:         "should_use_secondary_when_primary_is_unavailable",
+			resolvers:    []*stubResolver{{name: "first", err: errUnavailable}, {name: "second"}},
+			wantProvider: "second",
+		},
+		{
+			name:         "should_fall_back_to_remaining_providers",
+			resolvers:    []*stubResolver{{name: "first", err: errUnavailable}, {name: "second", err: errUnavailable}, {name: "third"}},
+			wantProvider: "third",
+		},
+		{
+			name:      "should_trust_primary_not_found",
+			resolvers: []*stubResolver{{name: "first", err: errNotFound}, {name: "second"}},
+			wantCause: app.ErrAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composite := NewComposite(providers(tt.resolvers...), log.WithField("test", tt.name)).WithConsensus(sameName)
+
+			account, err := composite.ResolveAccount(context.Background(), "035", "7811035835")
+			if tt.wantCause != nil {
+				assert.Equal(t, tt.wantCause, errors.Cause(err))
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantProvider, account.Provider)
+			if tt.wantDisagreement == "" {
+				assert.Nil(t, account.Disagreement)
+			} else if assert.NotNil(t, account.Disagreement) {
+				assert.Equal(t, tt.wantDisagreement, account.Disagreement.AccountName)
+			}
+		})
+	}
+}
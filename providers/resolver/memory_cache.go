@@ -0,0 +1,92 @@
+package resolver
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+)
+
+const defaultMemoryCacheSize = 10000
+
+// MemoryCache is an app.BankAccountCache keeping the most recently used accounts in memory,
+// the least recently used account is evicted once it holds size accounts
+type MemoryCache struct {
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func NewMemoryCache(size int) *MemoryCache {
+	if size <= 0 {
+		size = defaultMemoryCacheSize
+	}
+
+	return &MemoryCache{
+		size:    size,
+		now:     time.Now,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func cacheKey(bankCode, accountNumber string) string {
+	return bankCode + ":" + accountNumber
+}
+
+func (m *MemoryCache) FindCachedBankAccount(ctx context.Context, bankCode string, accountNumber string) (*app.CachedBankAccount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[cacheKey(bankCode, accountNumber)]
+	if !ok {
+		return nil, nil
+	}
+
+	account := element.Value.(*app.CachedBankAccount)
+	if !account.ExpiresAt.After(m.now()) {
+		m.remove(element)
+		return nil, nil
+	}
+
+	m.order.MoveToFront(element)
+	cached := *account
+	return &cached, nil
+}
+
+func (m *MemoryCache) SaveCachedBankAccount(ctx context.Context, account *app.CachedBankAccount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cached := *account
+	key := cacheKey(account.BankCode, account.AccountNumber)
+	if element, ok := m.entries[key]; ok {
+		element.Value = &cached
+		m.order.MoveToFront(element)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(&cached)
+	for m.order.Len() > m.size {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of accounts in the cache, expired ones included until they are looked up or evicted
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+func (m *MemoryCache) remove(element *list.Element) {
+	account := m.order.Remove(element).(*app.CachedBankAccount)
+	delete(m.entries, cacheKey(account.BankCode, account.AccountNumber))
+}
@@ -0,0 +1,52 @@
+package resolver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache(2)
+	cache.now = func() time.Time { return now }
+
+	save := func(accountNumber string, ttl time.Duration) {
+		err := cache.SaveCachedBankAccount(context.Background(), &app.CachedBankAccount{
+			BankCode:      "035",
+			AccountNumber: accountNumber,
+			AccountName:   "DANIEL OLUOJOMU",
+			ExpiresAt:     now.Add(ttl),
+		})
+		assert.NoError(t, err)
+	}
+	find := func(accountNumber string) *app.CachedBankAccount {
+		account, err := cache.FindCachedBankAccount(context.Background(), "035", accountNumber)
+		assert.NoError(t, err)
+		return account
+	}
+
+	save("0000000001", time.Hour)
+	save("0000000002", time.Hour)
+	assert.NotNil(t, find("0000000001"))
+
+	// 0000000002 is the least recently used account and makes room for 0000000003
+	save("0000000003", time.Hour)
+	assert.Equal(t, 2, cache.Len())
+	assert.Nil(t, find("0000000002"))
+	assert.NotNil(t, find("0000000001"))
+	assert.NotNil(t, find("0000000003"))
+
+	// saving an account again replaces it
+	save("0000000001", time.Minute)
+	assert.Equal(t, 2, cache.Len())
+
+	// expired accounts are removed when looked up
+	now = now.Add(time.Minute)
+	assert.Nil(t, find("0000000001"))
+	assert.Equal(t, 1, cache.Len())
+	assert.Nil(t, find("0000000004"))
+}
@@ -0,0 +1,55 @@
+package buycoin_challenge2
+
+import (
+	"context"
+	"time"
+)
+
+// Session is created when a user logs in, the tokens issued for it stop being accepted
+// once it expires or is revoked. Token and RefreshToken are the tokens most recently
+// issued for the session and are never stored
+type Session struct {
+	ID             string     `json:"id" gorm:"default:gen_random_uuid()"`
+	UserID         string     `json:"user_id"`
+	User           *User      `json:"user" gorm:"-"`
+	Token          string     `json:"token" gorm:"-"`
+	TokenExpiresAt time.Time  `json:"token_expires_at" gorm:"-"`
+	RefreshToken   string     `json:"refresh_token" gorm:"-"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Active reports whether tokens issued for the session are still accepted at now
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *Session) error
+	FindSessionByID(ctx context.Context, id string) (*Session, error)
+	RevokeSession(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeUserSessions(ctx context.Context, userID string, revokedAt time.Time) error
+	DeleteAllSessions() error
+}
+
+// RefreshToken renews a session's tokens once, using it replaces it with a new one. All the
+// refresh tokens of a session form a family, and a used token being sent again means one of
+// them was stolen so the whole session is revoked. Only a hash of the token is stored
+type RefreshToken struct {
+	ID        string     `json:"id" gorm:"default:gen_random_uuid()"`
+	SessionID string     `json:"session_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RefreshTokenRepository stores refresh tokens, MarkRefreshTokenUsed reports false
+// when the token had already been used
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	DeleteAllRefreshTokens() error
+}
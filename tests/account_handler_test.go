@@ -4,25 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/99designs/gqlgen/graphql"
 	app "github.com/danvixent/buycoin-challenge2"
 	"github.com/danvixent/buycoin-challenge2/password"
+	"github.com/danvixent/buycoin-challenge2/providers/paystack"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestRegisterUser(t *testing.T) {
-	err := deleteAllUserBankAccounts()
-	if !assert.NoError(t, err) {
-		return
-	}
-
-	err = deleteAllUsers()
+	err := resetDatabase()
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -34,6 +32,7 @@ func TestRegisterUser(t *testing.T) {
 		wantErr      bool
 		checkData    bool
 		errorMessage string
+		wantEmail    string
 	}{
 		{
 			name:      "should_register_user_for_correct_input",
@@ -78,6 +77,41 @@ func TestRegisterUser(t *testing.T) {
 			wantErr:      true,
 			errorMessage: "Field UserRegistrationInput.password of required type String! was not provided.",
 		},
+		{
+			name:      "should_store_email_lowercased",
+			checkData: true,
+			wantCode:  http.StatusOK,
+			gqlQuery: `
+					mutation{
+  						registerUser(userDetails: {
+    						name:"Ada"
+							password:"password"
+    						email:" Ada@Gmail.com "
+  						}){
+    						id
+    						email
+    						name
+  						}
+					}`,
+			wantEmail: "ada@gmail.com",
+		},
+		{
+			name:      "should_error_for_email_registered_in_other_case",
+			checkData: false,
+			wantCode:  http.StatusOK,
+			gqlQuery: `
+					mutation{
+  						registerUser(userDetails: {
+    						name:"Ada"
+							password:"password"
+    						email:"ADA@gmail.com"
+  						}){
+    						id
+  						}
+					}`,
+			wantErr:      true,
+			errorMessage: "email already registered",
+		},
 	}
 
 	for _, tt := range tests {
@@ -110,6 +144,9 @@ func TestRegisterUser(t *testing.T) {
 				assert.NotEmpty(t, body.Data.RegisterUser.ID)
 				assert.NotEmpty(t, body.Data.RegisterUser.Name)
 				assert.NotEmpty(t, body.Data.RegisterUser.Email)
+				if tt.wantEmail != "" {
+					assert.Equal(t, tt.wantEmail, body.Data.RegisterUser.Email)
+				}
 			}
 
 			if tt.wantErr {
@@ -120,12 +157,7 @@ func TestRegisterUser(t *testing.T) {
 }
 
 func TestAddBankAccount(t *testing.T) {
-	err := deleteAllUserBankAccounts()
-	if !assert.NoError(t, err) {
-		return
-	}
-
-	err = deleteAllUsers()
+	err := resetDatabase()
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -141,6 +173,11 @@ func TestAddBankAccount(t *testing.T) {
 		return
 	}
 
+	token, err := login(user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
 	tests := []struct {
 		wantCode     int
 		name         string
@@ -148,6 +185,8 @@ func TestAddBankAccount(t *testing.T) {
 		wantErr      bool
 		checkData    bool
 		errorMessage string
+		// wantAttempt is the verification attempt the request should record, nil when none should be
+		wantAttempt *app.VerificationAttempt
 	}{
 		{
 			name:      "should_register_user_for_correct_input",
@@ -155,15 +194,22 @@ func TestAddBankAccount(t *testing.T) {
 			wantCode:  http.StatusOK,
 			gqlQuery: `
 					mutation{
-  						addBankAccount(user_id:"%s"
-  						input:{
+  						addBankAccount(input:{
     						user_bank_code:"035"
     						user_account_name:"Daniel Oluojomu"
-    						user_account_number:"7811035835"
+    						user_account_number:"7811035832"
 						})
 					}`,
 			wantErr:      false,
 			errorMessage: "",
+			wantAttempt: &app.VerificationAttempt{
+				AccountNumber: "7811035832",
+				ResolvedName:  "DANIEL OLUOJOMU",
+				Provider:      paystack.ProviderName,
+				Strategy:      "token",
+				Score:         1,
+				Decision:      app.DecisionApproved,
+			},
 		},
 		{
 			name:      "should_error_for_duplicate_account",
@@ -171,40 +217,81 @@ func TestAddBankAccount(t *testing.T) {
 			wantCode:  http.StatusOK,
 			gqlQuery: `
 					mutation{
-  						addBankAccount(user_id:"%s"
-  						input:{
+  						addBankAccount(input:{
     						user_bank_code:"035"
     						user_account_name:"Daniel Oluojomu"
-    						user_account_number:"7811035835"
+    						user_account_number:"7811035832"
 						})
 					}`,
 			wantErr:      true,
 			errorMessage: "bank account already saved",
+			wantAttempt: &app.VerificationAttempt{
+				AccountNumber: "7811035832",
+				ResolvedName:  "DANIEL OLUOJOMU",
+				Provider:      paystack.ProviderName,
+				Strategy:      "token",
+				Score:         1,
+				Decision:      app.DecisionApproved,
+			},
 		},
 		{
 			name: "should_error_for_wrong_input",
 			gqlQuery: `
 					mutation{
-  						addBankAccount(user_id:"%s"
-  						input:{
+  						addBankAccount(input:{
     						user_bank_code:"030"
     						user_account_name:"Daniel Oluojomu"
-    						user_account_number:"7811035835"
+    						user_account_number:"7811035832"
+						})
+					}`,
+			checkData:    false,
+			wantCode:     http.StatusOK,
+			wantErr:      true,
+			errorMessage: "account number is not valid for this bank",
+		},
+		{
+			name: "should_error_for_unknown_account",
+			gqlQuery: `
+					mutation{
+  						addBankAccount(input:{
+    						user_bank_code:"058"
+    						user_account_name:"Daniel Oluojomu"
+    						user_account_number:"0000000506"
+						})
+					}`,
+			checkData:    false,
+			wantCode:     http.StatusOK,
+			wantErr:      true,
+			errorMessage: "bank account not found",
+			wantAttempt:  &app.VerificationAttempt{AccountNumber: "0000000506", Decision: app.DecisionResolutionFailed},
+		},
+		{
+			name: "should_error_for_unknown_bank_code",
+			gqlQuery: `
+					mutation{
+  						addBankAccount(input:{
+    						user_bank_code:"999"
+    						user_account_name:"Daniel Oluojomu"
+    						user_account_number:"7811035832"
 						})
 					}`,
 			checkData:    false,
 			wantCode:     http.StatusOK,
 			wantErr:      true,
-			errorMessage: "failed to resolve user bank account",
+			errorMessage: "unknown bank code",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			query := fmt.Sprintf(tt.gqlQuery, user.ID)
-			gql := graphql.RawParams{Query: query}
+			before, err := verificationRepo.FindVerificationAttemptsByUserID(context.Background(), user.ID)
+			if !assert.NoError(t, err) {
+				return
+			}
 
-			resp, err := sendRequest(gql)
+			gql := graphql.RawParams{Query: tt.gqlQuery}
+
+			resp, err := sendAuthenticatedRequest(gql, token)
 			if err != nil {
 				t.Errorf("sendRequest() error = %v", err)
 				return
@@ -233,17 +320,323 @@ func TestAddBankAccount(t *testing.T) {
 			if tt.wantErr {
 				assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
 			}
+
+			attempts, err := verificationRepo.FindVerificationAttemptsByUserID(context.Background(), user.ID)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.wantAttempt == nil {
+				assert.Len(t, attempts, len(before))
+				return
+			}
+
+			// attempts are listed newest first
+			if assert.Len(t, attempts, len(before)+1) {
+				attempt := attempts[0]
+				assert.Equal(t, tt.wantAttempt.AccountNumber, attempt.AccountNumber)
+				assert.Equal(t, tt.wantAttempt.ResolvedName, attempt.ResolvedName)
+				assert.Equal(t, tt.wantAttempt.Provider, attempt.Provider)
+				assert.Equal(t, tt.wantAttempt.Strategy, attempt.Strategy)
+				assert.Equal(t, tt.wantAttempt.Score, attempt.Score)
+				assert.Equal(t, tt.wantAttempt.Decision, attempt.Decision)
+			}
 		})
 	}
 }
 
-func TestResolveAccount(t *testing.T) {
-	err := deleteAllUserBankAccounts()
+func TestAddBankAccountProviderUnavailable(t *testing.T) {
+	if fakePaystack == nil {
+		t.Skip("paystack failures can only be injected into the fake paystack server")
+	}
+
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, err := login(user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// fail every attempt the paystack client makes
+	fakePaystack.FailNext(10, http.StatusServiceUnavailable)
+	defer fakePaystack.FailNext(0, 0)
+
+	query := `
+					mutation{
+  						addBankAccount(input:{
+    						user_bank_code:"035"
+    						user_account_name:"Daniel Oluojomu"
+    						user_account_number:"7811035832"
+						})
+					}`
+
+	resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: query}, token)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	body := &struct {
+		Errors []struct{ Message string }
+	}{}
+	err = getResponseData(resp.Body, body)
+	if assert.NoError(t, err) && assert.NotEmpty(t, body.Errors) {
+		assert.Equal(t, "bank account provider unavailable, please try again later", body.Errors[0].Message)
+	}
+}
+
+func TestReviewBankAccount(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	admin := &app.User{
+		Email:    "admin@gmail.live",
+		Name:     "Admin",
+		Password: generateHash("password"),
+		IsAdmin:  true,
+	}
+	err = userRepo.CreateUser(context.Background(), admin)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	adminToken, err := login(admin)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	saveBankAccount := func(user *app.User, accountNumber string, status string) (*app.UserBankAccount, error) {
+		account := &app.UserBankAccount{
+			UserID: user.ID,
+			BankAccount: &app.BankAccount{
+				UserBankCode:      "035",
+				UserAccountName:   "Daniel Oluojomu",
+				UserAccountNumber: accountNumber,
+			},
+			Status: status,
+		}
+		return account, userRepo.SaveUserBankAccount(context.Background(), account)
+	}
+
+	tests := []struct {
+		name         string
+		mutation     string
+		asOwner      bool
+		seed         func(user *app.User) error
+		wantVerified bool
+		wantStatus   string
+		errorMessage string
+	}{
+		{
+			name:         "should_verify_user_on_approve",
+			mutation:     "approveBankAccount",
+			wantVerified: true,
+			wantStatus:   app.BankAccountStatusVerified,
+		},
+		{
+			name:         "should_leave_user_unverified_on_reject",
+			mutation:     "rejectBankAccount",
+			wantVerified: false,
+			wantStatus:   app.BankAccountStatusRejected,
+		},
+		{
+			name:     "should_keep_user_verified_by_another_bank_account_on_reject",
+			mutation: "rejectBankAccount",
+			seed: func(user *app.User) error {
+				_, err := saveBankAccount(user, "0123456785", app.BankAccountStatusVerified)
+				return err
+			},
+			wantVerified: true,
+			wantStatus:   app.BankAccountStatusRejected,
+		},
+		{
+			name:     "should_keep_user_verified_by_bvn_on_reject",
+			mutation: "rejectBankAccount",
+			seed: func(user *app.User) error {
+				return identityRepo.SaveUserIdentity(context.Background(), &app.UserIdentity{
+					UserID:      user.ID,
+					MaskedBVN:   "*******5678",
+					DateOfBirth: time.Date(1995, 6, 14, 0, 0, 0, 0, time.UTC),
+					Decision:    app.DecisionApproved,
+					Status:      app.IdentityStatusVerified,
+				})
+			},
+			wantVerified: true,
+			wantStatus:   app.BankAccountStatusRejected,
+		},
+		{
+			name:         "should_refuse_approve_from_non_admin",
+			mutation:     "approveBankAccount",
+			asOwner:      true,
+			wantStatus:   app.BankAccountStatusPending,
+			errorMessage: "admin access required",
+		},
+		{
+			name:         "should_refuse_reject_from_non_admin",
+			mutation:     "rejectBankAccount",
+			asOwner:      true,
+			wantStatus:   app.BankAccountStatusPending,
+			errorMessage: "admin access required",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &app.User{
+				Email:    fmt.Sprintf("review%d@gmail.live", i),
+				Name:     "Daniel",
+				Password: generateHash("password"),
+			}
+			err := userRepo.CreateUser(context.Background(), user)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.seed != nil {
+				err = tt.seed(user)
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+
+			account, err := saveBankAccount(user, "7811035832", app.BankAccountStatusPending)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			token := adminToken
+			if tt.asOwner {
+				token, err = login(user)
+				if !assert.NoError(t, err) {
+					return
+				}
+			}
+
+			gql := graphql.RawParams{Query: fmt.Sprintf(`mutation{ %s(id:"%s") }`, tt.mutation, account.ID)}
+			resp, err := sendAuthenticatedRequest(gql, token)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+			}{}
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.errorMessage != "" {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+			} else {
+				assert.Empty(t, body.Errors)
+			}
+
+			reviewed, err := userRepo.FindUserBankAccountByID(context.Background(), account.ID)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantStatus, reviewed.Status)
+			assert.Equal(t, tt.wantVerified, reviewed.User.Verified)
+		})
+	}
+}
+
+func TestPendingBankAccounts(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	admin := &app.User{Email: "admin@gmail.live", Name: "Admin", Password: generateHash("password"), IsAdmin: true}
+	user := &app.User{Email: "dan@gmail.live", Name: "Daniel", Password: generateHash("password")}
+	for _, u := range []*app.User{admin, user} {
+		err = userRepo.CreateUser(context.Background(), u)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+
+	err = userRepo.SaveUserBankAccount(context.Background(), &app.UserBankAccount{
+		UserID: user.ID,
+		BankAccount: &app.BankAccount{
+			UserBankCode:      "035",
+			UserAccountName:   "Daniel Oluojomu",
+			UserAccountNumber: "7811035832",
+		},
+		Status: app.BankAccountStatusPending,
+	})
 	if !assert.NoError(t, err) {
 		return
 	}
 
-	err = deleteAllUsers()
+	tests := []struct {
+		name         string
+		user         *app.User
+		wantCount    int
+		errorMessage string
+	}{
+		{name: "should_list_pending_accounts_for_admin", user: admin, wantCount: 1},
+		{name: "should_refuse_non_admin", user: user, errorMessage: "admin access required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := login(tt.user)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			gql := graphql.RawParams{Query: `query{ pendingBankAccounts{ id status } }`}
+			resp, err := sendAuthenticatedRequest(gql, token)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   *struct {
+					PendingBankAccounts []struct{ ID, Status string }
+				}
+			}{}
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.errorMessage != "" {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+				return
+			}
+
+			assert.Empty(t, body.Errors)
+			if assert.NotNil(t, body.Data) && assert.Len(t, body.Data.PendingBankAccounts, tt.wantCount) {
+				assert.Equal(t, app.BankAccountStatusPending, body.Data.PendingBankAccounts[0].Status)
+			}
+		})
+	}
+}
+
+func TestResolveAccount(t *testing.T) {
+	err := resetDatabase()
 	if !assert.NoError(t, err) {
 		return
 	}
@@ -263,10 +656,11 @@ func TestResolveAccount(t *testing.T) {
 		UserID: user.ID,
 		User:   user,
 		BankAccount: &app.BankAccount{
-			UserAccountNumber: "7811035835",
+			UserAccountNumber: "7811035832",
 			UserBankCode:      "035",
 			UserAccountName:   "Daniel Oluojomu",
 		},
+		Status: app.BankAccountStatusVerified,
 	}
 
 	err = userRepo.SaveUserBankAccount(context.Background(), account)
@@ -289,7 +683,7 @@ func TestResolveAccount(t *testing.T) {
 					query{
   						resolveAccount(
     						bank_code:"035"
-    						account_number:"7811035835"
+    						account_number:"7811035832"
   						)
 					}`,
 			wantErr:         false,
@@ -306,12 +700,12 @@ func TestResolveAccount(t *testing.T) {
 					query{
   						resolveAccount(
     						bank_code:"02"
-    						account_number:"7811035835"
+    						account_number:"7811035832"
   						)
 					}`,
 			wantErr:         true,
 			wantAccountName: "",
-			errorMessage:    "find user bank account failed",
+			errorMessage:    "unknown bank code",
 		},
 	}
 
@@ -348,29 +742,750 @@ func TestResolveAccount(t *testing.T) {
 	}
 }
 
-func generateHash(s string) *password.Hash {
-	hash, err := password.NewPasswordHash(s)
-	if err != nil {
-		log.Panicf("failed to generate hash: %v", err)
+func TestVerificationAttempts(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
 	}
-	return hash
-}
 
-func getResponseData(respBody io.ReadCloser, data interface{}) error {
-	responseData, err := ioutil.ReadAll(respBody)
-	if err != nil {
-		return err
+	// seed the user with the attempt, another user and an admin
+	user := &app.User{Email: "danv@gmail.live", Name: "Daniel", Password: generateHash("password")}
+	other := &app.User{Email: "fatima@gmail.live", Name: "Fatima", Password: generateHash("password")}
+	admin := &app.User{Email: "admin@gmail.live", Name: "Admin", Password: generateHash("password"), IsAdmin: true}
+	for _, u := range []*app.User{user, other, admin} {
+		err = userRepo.CreateUser(context.Background(), u)
+		if !assert.NoError(t, err) {
+			return
+		}
 	}
-	fmt.Println("resp", string(responseData))
-	if err = json.Unmarshal(responseData, data); err != nil {
-		return err
+
+	attempt := &app.VerificationAttempt{
+		UserID:        user.ID,
+		BankCode:      "035",
+		AccountNumber: "7811035832",
+		SubmittedName: "Daniel Oluojomu",
+		ResolvedName:  "OLUOJOMU DANIEL AYO",
+		Strategy:      "token",
+		Score:         0.9,
+		Decision:      app.DecisionApproved,
+	}
+	err = verificationRepo.SaveVerificationAttempt(context.Background(), attempt)
+	if !assert.NoError(t, err) {
+		return
 	}
 
-	return nil
-}
+	tests := []struct {
+		name         string
+		user         *app.User
+		errorMessage string
+	}{
+		{name: "should_list_own_attempts", user: user},
+		{name: "should_list_attempts_of_another_user_for_admin", user: admin},
+		{name: "should_refuse_another_user", user: other, errorMessage: "you can only access your own account"},
+	}
 
-func sendRequest(body interface{}) (*http.Response, error) {
-	return POST(baseURL, serialize(body))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := login(tt.user)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			query := fmt.Sprintf(`
+					query{
+  						verificationAttempts(user_id:"%s"){
+    						id
+    						submitted_name
+    						resolved_name
+    						strategy
+    						score
+    						distance
+    						decision
+    						created_at
+  						}
+					}`, user.ID)
+
+			resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: query}, token)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   *struct{ VerificationAttempts []*app.VerificationAttempt }
+			}{}
+
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.errorMessage != "" {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+				return
+			}
+
+			assert.Empty(t, body.Errors)
+			if assert.NotNil(t, body.Data) && assert.Len(t, body.Data.VerificationAttempts, 1) {
+				got := body.Data.VerificationAttempts[0]
+				assert.Equal(t, attempt.ID, got.ID)
+				assert.Equal(t, "OLUOJOMU DANIEL AYO", got.ResolvedName)
+				assert.Equal(t, app.DecisionApproved, got.Decision)
+			}
+		})
+	}
+}
+
+func TestVerifyBVN(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// seed one user with a date of birth and one without
+	dateOfBirth := time.Date(1995, time.June, 14, 0, 0, 0, 0, time.UTC)
+	user := &app.User{
+		Email:       "dan@gmail.live",
+		Name:        "Daniel Oluojomu",
+		Password:    generateHash("password"),
+		DateOfBirth: &dateOfBirth,
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	userWithoutDateOfBirth := &app.User{
+		Email:    "fatima@gmail.live",
+		Name:     "Fatima Bello",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), userWithoutDateOfBirth)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, err := login(user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	otherToken, err := login(userWithoutDateOfBirth)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		bvn          string
+		dateOfBirth  string
+		wantVerified bool
+		wantErr      bool
+		errorMessage string
+	}{
+		{
+			name:         "should_verify_matching_bvn",
+			token:        token,
+			bvn:          "22212345678",
+			wantVerified: true,
+		},
+		{
+			name:         "should_error_for_mismatching_bvn",
+			token:        token,
+			bvn:          "22287654321",
+			wantErr:      true,
+			errorMessage: "bvn details do not match",
+		},
+		{
+			name:         "should_error_for_unknown_bvn",
+			token:        token,
+			bvn:          "22200000000",
+			wantErr:      true,
+			errorMessage: "bvn not found",
+		},
+		{
+			name:         "should_error_for_invalid_bvn",
+			token:        token,
+			bvn:          "2221234",
+			wantErr:      true,
+			errorMessage: "bvn must be 11 digits",
+		},
+		{
+			name:         "should_error_without_date_of_birth",
+			token:        otherToken,
+			bvn:          "22287654321",
+			wantErr:      true,
+			errorMessage: "date of birth is required to verify bvn",
+		},
+		{
+			name:         "should_error_for_supplied_date_of_birth_not_matching_bvn",
+			token:        otherToken,
+			bvn:          "22287654321",
+			dateOfBirth:  "1990-01-31",
+			wantErr:      true,
+			errorMessage: "bvn details do not match",
+		},
+		{
+			name:         "should_verify_with_supplied_date_of_birth",
+			token:        otherToken,
+			bvn:          "22287654321",
+			dateOfBirth:  "1990-01-30",
+			wantVerified: true,
+		},
+		{
+			name:         "should_error_without_token",
+			bvn:          "22212345678",
+			wantErr:      true,
+			errorMessage: "authentication required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := fmt.Sprintf(`bvn:"%s"`, tt.bvn)
+			if tt.dateOfBirth != "" {
+				args += fmt.Sprintf(`, date_of_birth:"%s"`, tt.dateOfBirth)
+			}
+
+			query := fmt.Sprintf(`
+					mutation{
+  						verifyBVN(%s)
+					}`, args)
+
+			resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: query}, tt.token)
+			if err != nil {
+				t.Errorf("sendRequest() error = %v", err)
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   struct{ VerifyBVN bool }
+			}{}
+
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.wantErr {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+				return
+			}
+
+			assert.Empty(t, body.Errors)
+			assert.Equal(t, tt.wantVerified, body.Data.VerifyBVN)
+		})
+	}
+
+	verified, err := userRepo.FindUserByID(context.Background(), user.ID)
+	if assert.NoError(t, err) {
+		assert.True(t, verified.Verified)
+	}
+
+	// the date of birth that matched the bvn is kept for users registered without one
+	verified, err = userRepo.FindUserByID(context.Background(), userWithoutDateOfBirth.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, verified.DateOfBirth) {
+		assert.True(t, verified.Verified)
+		assert.Equal(t, "1990-01-30", verified.DateOfBirth.Format("2006-01-02"))
+	}
+}
+
+func TestLogin(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel Oluojomu",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name         string
+		email        string
+		password     string
+		wantErr      bool
+		errorMessage string
+	}{
+		{
+			name:     "should_login",
+			email:    "dan@gmail.live",
+			password: "password",
+		},
+		{
+			name:     "should_login_with_differently_cased_email",
+			email:    "Dan@Gmail.live",
+			password: "password",
+		},
+		{
+			name:         "should_error_for_wrong_password",
+			email:        "dan@gmail.live",
+			password:     "wrong password",
+			wantErr:      true,
+			errorMessage: "invalid email or password",
+		},
+		{
+			name:         "should_error_for_unknown_email",
+			email:        "fatima@gmail.live",
+			password:     "password",
+			wantErr:      true,
+			errorMessage: "invalid email or password",
+		},
+		{
+			name:         "should_error_without_password",
+			email:        "dan@gmail.live",
+			wantErr:      true,
+			errorMessage: "password is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := fmt.Sprintf(`
+					mutation{
+  						login(email:"%s", password:"%s"){
+							token
+							token_expires_at
+							expires_at
+							user{
+								id
+							}
+						}
+					}`, tt.email, tt.password)
+
+			resp, err := sendRequest(graphql.RawParams{Query: query})
+			if err != nil {
+				t.Errorf("sendRequest() error = %v", err)
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   struct {
+					Login *struct {
+						Token          string
+						TokenExpiresAt string `json:"token_expires_at"`
+						ExpiresAt      string `json:"expires_at"`
+						User           struct{ ID string }
+					}
+				}
+			}{}
+
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.wantErr {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+				return
+			}
+
+			if !assert.Empty(t, body.Errors) || !assert.NotNil(t, body.Data.Login) {
+				return
+			}
+			assert.NotEmpty(t, body.Data.Login.TokenExpiresAt)
+			assert.NotEmpty(t, body.Data.Login.ExpiresAt)
+
+			claims, err := tokenManager.Parse(body.Data.Login.Token)
+			if assert.NoError(t, err) {
+				assert.Equal(t, user.ID, claims.Subject)
+			}
+
+			// the user was seeded with a scrypt hash using the default parameters, which logging in
+			// replaces with a hash using the configured algorithm and parameters
+			stored, err := userRepo.FindUserByID(context.Background(), user.ID)
+			if assert.NoError(t, err) {
+				assert.False(t, hasher.NeedsRehash(stored.Password))
+				assert.True(t, stored.Password.Verify(tt.password))
+			}
+			assert.Equal(t, user.ID, body.Data.Login.User.ID)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel Oluojomu",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, err := login(user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// a token that is still valid for a session that has already expired
+	expiredSession := &app.Session{UserID: user.ID, ExpiresAt: time.Now().Add(-time.Minute)}
+	err = sessionRepo.CreateSession(context.Background(), expiredSession)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expiredSessionToken, _, err := tokenManager.Issue(expiredSession)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session := &app.Session{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	err = sessionRepo.CreateSession(context.Background(), session)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expiredToken, err := issueExpiredToken(session)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		errorMessage string
+	}{
+		{
+			name:  "should_authenticate_token",
+			token: token,
+		},
+		{
+			name:         "should_error_without_token",
+			errorMessage: "authentication required",
+		},
+		{
+			name:         "should_reject_malformed_token",
+			token:        "not.a.token",
+			errorMessage: "invalid token",
+		},
+		{
+			name:         "should_reject_expired_token",
+			token:        expiredToken,
+			errorMessage: "token expired",
+		},
+		{
+			name:         "should_reject_token_for_expired_session",
+			token:        expiredSessionToken,
+			errorMessage: "invalid token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.errorMessage, sendTokenCheck(t, tt.token, user.ID))
+		})
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel Oluojomu",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session, err := sendLogin(user.Email, "password")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	refreshed, err := sendRefreshSession(session.RefreshToken)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(t, session.ExpiresAt, refreshed.ExpiresAt)
+
+	claims, err := tokenManager.Parse(refreshed.Token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.ID, claims.Subject)
+	}
+
+	// sending the used refresh token again revokes the session along with the refresh token that replaced it
+	_, err = sendRefreshSession(session.RefreshToken)
+	assert.EqualError(t, err, "invalid refresh token")
+
+	_, err = sendRefreshSession(refreshed.RefreshToken)
+	assert.EqualError(t, err, "invalid refresh token")
+
+	assert.Equal(t, "invalid token", sendTokenCheck(t, refreshed.Token, user.ID))
+
+	_, err = sendRefreshSession("not a refresh token")
+	assert.EqualError(t, err, "invalid refresh token")
+}
+
+func TestRefreshSessionWithExpiredToken(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel Oluojomu",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	session, err := sendLogin(user.Email, "password")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	claims, err := tokenManager.Parse(session.Token)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// clients send their expired token along with the refresh token, which must not get in the way
+	expiredToken, err := issueExpiredToken(&app.Session{ID: claims.SessionID, UserID: user.ID})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	query := fmt.Sprintf(`
+					mutation{
+  						refreshSession(refresh_token:"%s"){
+							token
+						}
+					}`, session.RefreshToken)
+
+	resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: query}, expiredToken)
+	if !assert.NoError(t, err) || !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+
+	body := &struct {
+		Errors []struct{ Message string }
+		Data   struct {
+			RefreshSession *struct{ Token string }
+		}
+	}{}
+
+	err = getResponseData(resp.Body, body)
+	if assert.NoError(t, err) && assert.Empty(t, body.Errors) && assert.NotNil(t, body.Data.RefreshSession) {
+		assert.Equal(t, "", sendTokenCheck(t, body.Data.RefreshSession.Token, user.ID))
+	}
+}
+
+func TestLogout(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel Oluojomu",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	phone, err := sendLogin(user.Email, "password")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	laptop, err := sendLogin(user.Email, "password")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tablet, err := sendLogin(user.Email, "password")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: `mutation{ logout }`}, phone.Token)
+	if !assert.NoError(t, err) || !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+
+	// only the phone is logged out
+	assert.Equal(t, "invalid token", sendTokenCheck(t, phone.Token, user.ID))
+	assert.Equal(t, "", sendTokenCheck(t, laptop.Token, user.ID))
+	_, err = sendRefreshSession(phone.RefreshToken)
+	assert.EqualError(t, err, "invalid refresh token")
+
+	resp, err = sendAuthenticatedRequest(graphql.RawParams{Query: `mutation{ logoutAllSessions }`}, laptop.Token)
+	if !assert.NoError(t, err) || !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return
+	}
+
+	assert.Equal(t, "invalid token", sendTokenCheck(t, laptop.Token, user.ID))
+	assert.Equal(t, "invalid token", sendTokenCheck(t, tablet.Token, user.ID))
+	_, err = sendRefreshSession(tablet.RefreshToken)
+	assert.EqualError(t, err, "invalid refresh token")
+
+	resp, err = sendRequest(graphql.RawParams{Query: `mutation{ logout }`})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	body := &struct {
+		Errors []struct{ Message string }
+	}{}
+	err = getResponseData(resp.Body, body)
+	if assert.NoError(t, err) && assert.NotEmpty(t, body.Errors) {
+		assert.Equal(t, "authentication required", body.Errors[0].Message)
+	}
+}
+
+type sessionResponse struct {
+	Token        string
+	RefreshToken string `json:"refresh_token"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+func sendLogin(email string, password string) (*sessionResponse, error) {
+	query := fmt.Sprintf(`
+					mutation{
+  						login(email:"%s", password:"%s"){
+							token
+							refresh_token
+							expires_at
+						}
+					}`, email, password)
+	return sendSessionRequest(query, "login")
+}
+
+func sendRefreshSession(refreshToken string) (*sessionResponse, error) {
+	query := fmt.Sprintf(`
+					mutation{
+  						refreshSession(refresh_token:"%s"){
+							token
+							refresh_token
+							expires_at
+						}
+					}`, refreshToken)
+	return sendSessionRequest(query, "refreshSession")
+}
+
+// sendSessionRequest sends a mutation returning a session and returns it, or the first error in the response
+func sendSessionRequest(query string, field string) (*sessionResponse, error) {
+	resp, err := sendRequest(graphql.RawParams{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	body := &struct {
+		Errors []struct{ Message string }
+		Data   map[string]*sessionResponse
+	}{}
+
+	err = getResponseData(resp.Body, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body.Errors) > 0 {
+		return nil, errors.New(body.Errors[0].Message)
+	}
+	return body.Data[field], nil
+}
+
+// sendTokenCheck sends a query needing authentication with token and returns the first error, which
+// is empty when the token was accepted
+func sendTokenCheck(t *testing.T, token string, userID string) string {
+	query := fmt.Sprintf(`
+					query{
+  						verificationAttempts(user_id:"%s"){
+    						id
+  						}
+					}`, userID)
+
+	resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: query}, token)
+	if !assert.NoError(t, err) || !assert.Equal(t, http.StatusOK, resp.StatusCode) {
+		return ""
+	}
+
+	body := &struct {
+		Errors []struct{ Message string }
+	}{}
+
+	err = getResponseData(resp.Body, body)
+	if !assert.NoError(t, err) || len(body.Errors) == 0 {
+		return ""
+	}
+	return body.Errors[0].Message
+}
+
+func generateHash(s string) *password.Hash {
+	hash, err := password.NewPasswordHash(s)
+	if err != nil {
+		log.Panicf("failed to generate hash: %v", err)
+	}
+	return hash
+}
+
+func getResponseData(respBody io.ReadCloser, data interface{}) error {
+	responseData, err := ioutil.ReadAll(respBody)
+	if err != nil {
+		return err
+	}
+	fmt.Println("resp", string(responseData))
+	if err = json.Unmarshal(responseData, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func sendRequest(body interface{}) (*http.Response, error) {
+	return POST(baseURL, serialize(body))
+}
+
+func sendAuthenticatedRequest(body interface{}, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, baseURL, serialize(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return http.DefaultClient.Do(req)
 }
 
 // serialize obj into json bytes
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankAccountCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	// clear accounts left by earlier runs, which all expire well within a year
+	_, err := bankAccountCache.DeleteExpiredCachedBankAccounts(ctx, now.AddDate(1, 0, 0))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name    string
+		account *app.CachedBankAccount
+		// update is saved over account when set
+		update   *app.CachedBankAccount
+		wantName string
+		wantMiss bool
+	}{
+		{
+			name:     "should_find_cached_account",
+			account:  &app.CachedBankAccount{BankCode: "035", AccountNumber: "7811035832", AccountName: "DANIEL OLUOJOMU", Provider: "paystack", ExpiresAt: now.Add(time.Hour)},
+			wantName: "DANIEL OLUOJOMU",
+		},
+		{
+			name:    "should_find_missing_account",
+			account: &app.CachedBankAccount{BankCode: "058", AccountNumber: "0000000506", NotFound: true, ExpiresAt: now.Add(time.Hour)},
+		},
+		{
+			name:     "should_miss_expired_account",
+			account:  &app.CachedBankAccount{BankCode: "033", AccountNumber: "2034567896", AccountName: "CHUKWUEMEKA NNAMDI OBI", ExpiresAt: now.Add(-time.Minute)},
+			wantMiss: true,
+		},
+		{
+			name:     "should_replace_account_saved_again",
+			account:  &app.CachedBankAccount{BankCode: "044", AccountNumber: "1002003002", NotFound: true, ExpiresAt: now.Add(-time.Minute)},
+			update:   &app.CachedBankAccount{BankCode: "044", AccountNumber: "1002003002", AccountName: "FATIMA ALHAJA BELLO", ExpiresAt: now.Add(time.Hour)},
+			wantName: "FATIMA ALHAJA BELLO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bankAccountCache.SaveCachedBankAccount(ctx, tt.account)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			want := tt.account
+			if tt.update != nil {
+				err = bankAccountCache.SaveCachedBankAccount(ctx, tt.update)
+				if !assert.NoError(t, err) {
+					return
+				}
+				want = tt.update
+			}
+
+			cached, err := bankAccountCache.FindCachedBankAccount(ctx, want.BankCode, want.AccountNumber)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.wantMiss {
+				assert.Nil(t, cached)
+				return
+			}
+
+			if assert.NotNil(t, cached) {
+				assert.Equal(t, tt.wantName, cached.AccountName)
+				assert.Equal(t, want.NotFound, cached.NotFound)
+				assert.Equal(t, want.Provider, cached.Provider)
+			}
+		})
+	}
+
+	t.Run("should_delete_only_expired_accounts", func(t *testing.T) {
+		deleted, err := bankAccountCache.DeleteExpiredCachedBankAccounts(ctx, now)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(1), deleted)
+
+		// the expired account is gone, and the others still in the cache
+		deleted, err = bankAccountCache.DeleteExpiredCachedBankAccounts(ctx, now)
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(0), deleted)
+		}
+
+		cached, err := bankAccountCache.FindCachedBankAccount(ctx, "035", "7811035832")
+		if assert.NoError(t, err) {
+			assert.NotNil(t, cached)
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanks(t *testing.T) {
+	tests := []struct {
+		name      string
+		gqlQuery  string
+		wantCode  int
+		wantBanks []string
+	}{
+		{
+			name: "should_search_banks_by_name",
+			gqlQuery: `
+					query{
+  						banks(search:"wema"){
+    						code
+    						name
+    						active
+  						}
+					}`,
+			wantCode:  http.StatusOK,
+			wantBanks: []string{"035"},
+		},
+		{
+			name: "should_find_no_banks_for_unknown_name",
+			gqlQuery: `
+					query{
+  						banks(search:"no such bank"){
+    						code
+  						}
+					}`,
+			wantCode:  http.StatusOK,
+			wantBanks: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := sendRequest(graphql.RawParams{Query: tt.gqlQuery})
+			if err != nil {
+				t.Errorf("sendRequest() error = %v", err)
+				return
+			}
+
+			if !assert.Equal(t, tt.wantCode, resp.StatusCode) {
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   struct {
+					Banks []struct {
+						Code   string
+						Name   string
+						Active bool
+					}
+				}
+			}{}
+
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) || !assert.Empty(t, body.Errors) {
+				return
+			}
+
+			codes := []string{}
+			for _, bank := range body.Data.Banks {
+				codes = append(codes, bank.Code)
+			}
+			assert.Equal(t, tt.wantBanks, codes)
+		})
+	}
+}
+
+func TestSuggestBanks(t *testing.T) {
+	err := resetDatabase()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	user := &app.User{
+		Email:    "dan@gmail.live",
+		Name:     "Daniel",
+		Password: generateHash("password"),
+	}
+	err = userRepo.CreateUser(context.Background(), user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	token, err := login(user)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name          string
+		gqlQuery      string
+		anonymous     bool
+		wantCode      int
+		wantBanks     []string
+		wantConfirmed []bool
+		wantErr       bool
+		errorMessage  string
+	}{
+		{
+			name: "should_suggest_banks_for_account_number",
+			gqlQuery: `
+					query{
+  						suggestBanks(account_number:"7811035832"){
+    						bank{
+    							code
+    						}
+    						confirmed
+  						}
+					}`,
+			wantCode:      http.StatusOK,
+			wantBanks:     []string{"035", "057"},
+			wantConfirmed: []bool{false, false},
+		},
+		{
+			name: "should_confirm_suggested_banks",
+			gqlQuery: `
+					query{
+  						suggestBanks(account_number:"7811035832", confirm:true){
+    						bank{
+    							code
+    						}
+    						account_name
+    						confirmed
+  						}
+					}`,
+			wantCode:      http.StatusOK,
+			wantBanks:     []string{"035"},
+			wantConfirmed: []bool{true},
+		},
+		{
+			name: "should_error_for_invalid_account_number",
+			gqlQuery: `
+					query{
+  						suggestBanks(account_number:"78110"){
+    						confirmed
+  						}
+					}`,
+			wantCode:     http.StatusOK,
+			wantErr:      true,
+			errorMessage: "account number must be 10 digits",
+		},
+		{
+			name: "should_error_without_token",
+			gqlQuery: `
+					query{
+  						suggestBanks(account_number:"7811035832", confirm:true){
+    						confirmed
+  						}
+					}`,
+			anonymous:    true,
+			wantCode:     http.StatusOK,
+			wantErr:      true,
+			errorMessage: "authentication required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestToken := token
+			if tt.anonymous {
+				requestToken = ""
+			}
+
+			resp, err := sendAuthenticatedRequest(graphql.RawParams{Query: tt.gqlQuery}, requestToken)
+			if err != nil {
+				t.Errorf("sendRequest() error = %v", err)
+				return
+			}
+
+			if !assert.Equal(t, tt.wantCode, resp.StatusCode) {
+				return
+			}
+
+			body := &struct {
+				Errors []struct{ Message string }
+				Data   struct {
+					SuggestBanks []struct {
+						Bank        struct{ Code string }
+						AccountName string `json:"account_name"`
+						Confirmed   bool
+					}
+				}
+			}{}
+
+			err = getResponseData(resp.Body, body)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.wantErr {
+				if assert.NotEmpty(t, body.Errors) {
+					assert.Equal(t, tt.errorMessage, body.Errors[0].Message)
+				}
+				return
+			}
+
+			codes := []string{}
+			confirmed := []bool{}
+			for _, suggestion := range body.Data.SuggestBanks {
+				codes = append(codes, suggestion.Bank.Code)
+				confirmed = append(confirmed, suggestion.Confirmed)
+			}
+			assert.Equal(t, tt.wantBanks, codes)
+			assert.Equal(t, tt.wantConfirmed, confirmed)
+		})
+	}
+}
@@ -4,42 +4,109 @@ import (
 	"context"
 	"fmt"
 	app "github.com/danvixent/buycoin-challenge2"
+	"github.com/danvixent/buycoin-challenge2/auth"
 	"github.com/danvixent/buycoin-challenge2/config"
 	"github.com/danvixent/buycoin-challenge2/datastore/postgres"
 	"github.com/danvixent/buycoin-challenge2/graphql"
 	"github.com/danvixent/buycoin-challenge2/handlers/account"
+	"github.com/danvixent/buycoin-challenge2/handlers/bank"
+	"github.com/danvixent/buycoin-challenge2/password"
 	"github.com/danvixent/buycoin-challenge2/providers/paystack"
+	"github.com/danvixent/buycoin-challenge2/providers/paystack/fake"
+	"github.com/golang-jwt/jwt"
 	log "github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 )
 
 var (
-	baseURL  = "http://localhost:%s/graphql"
-	userRepo app.UserRepository
+	baseURL          = "http://localhost:%s/graphql"
+	userRepo         app.UserRepository
+	verificationRepo app.VerificationRepository
+	bankRepo         app.BankRepository
+	identityRepo     app.IdentityRepository
+	sessionRepo      app.SessionRepository
+	refreshTokenRepo app.RefreshTokenRepository
+	bankAccountCache app.PurgeableBankAccountCache
+	tokenManager     *auth.TokenManager
+	authConfig       *config.AuthConfig
+	hasher           password.Hasher
+	fakePaystack     *fake.Server
 )
 
 func TestMain(m *testing.M) {
-	file, err := os.Open("../config/config.yml")
+	cfg, err := config.Load("../config/config.yml")
 	if err != nil {
-		log.Fatalf("unable to open config file: %v", err)
+		log.Fatalf("failed to load config: %v", err)
 	}
 
-	cfg := &config.BaseConfig{}
-	err = yaml.NewDecoder(file).Decode(cfg)
+	postgresClient := postgres.New(context.Background(), cfg.Postgres)
+	userRepo = postgres.NewUserRepository(postgresClient)
+	verificationRepo = postgres.NewVerificationRepository(postgresClient)
+	bankRepo = postgres.NewBankRepository(postgresClient)
+	identityRepo = postgres.NewIdentityRepository(postgresClient)
+	sessionRepo = postgres.NewSessionRepository(postgresClient)
+	refreshTokenRepo = postgres.NewRefreshTokenRepository(postgresClient)
+	bankAccountCache = postgres.NewBankAccountCache(postgresClient)
+
+	// tests run against a fake paystack unless PAYSTACK_LIVE is set
+	paystackOptions := paystack.ConfigOptions(cfg.Paystack)
+	var fakePaystackServer *httptest.Server
+	if os.Getenv("PAYSTACK_LIVE") == "" {
+		fixtures, err := fake.LoadFixtures("../config/paystack_fixtures.yml")
+		if err != nil {
+			log.Fatalf("failed to load paystack fixtures: %v", err)
+		}
+
+		fakePaystack = fake.NewServer(fixtures)
+		fakePaystackServer = httptest.NewServer(fakePaystack)
+		paystackOptions = append(paystackOptions, paystack.WithBaseURL(fakePaystackServer.URL))
+	}
+	paystackClient := paystack.NewAPIClient(cfg.PaystackAPIKey, paystackOptions...)
+
+	var nameVariants *account.NameVariants
+	if cfg.NameMatcher != nil {
+		nameVariants, err = account.LoadNameVariants(cfg.NameMatcher.VariantsPath)
+		if err != nil {
+			log.Fatalf("failed to load name variants: %v", err)
+		}
+	}
+
+	nameMatcher, err := account.NewNameMatcher(cfg.NameMatcher, nameVariants)
 	if err != nil {
-		log.Fatalf("failed to decode config file: %v", err)
+		log.Fatalf("failed to create name matcher: %v", err)
 	}
 
-	postgresClient := postgres.New(context.Background(), cfg.Postgres)
-	userRepo = postgres.NewUserRepository(postgresClient)
-	paystackClient := paystack.NewAPIClient(cfg.PaystackAPIKey)
+	bankHandler := bank.NewHandler(bankRepo, paystackClient, paystackClient, cfg.BankDirectory)
+	if err = bankHandler.SyncBanks(context.Background(), log.WithField("component", "bank_directory")); err != nil {
+		log.Fatalf("failed to sync banks: %v", err)
+	}
+
+	// the config file has no signing keys, so the tests sign with a random one
+	authConfig = cfg.Auth
+	if authConfig == nil {
+		authConfig = &config.AuthConfig{}
+	}
+	secret, err := auth.NewOpaqueToken()
+	if err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
+	}
+	authConfig.SigningKeys = append([]config.SigningKey{{ID: "test", Secret: secret}}, authConfig.SigningKeys...)
+	tokenManager, err = auth.NewTokenManager(authConfig)
+	if err != nil {
+		log.Fatalf("failed to create token manager: %v", err)
+	}
+
+	hasher, err = password.NewHasher(cfg.Password)
+	if err != nil {
+		log.Fatalf("failed to create password hasher: %v", err)
+	}
 
-	accountHandler := account.NewHandler(userRepo, paystackClient)
-	graphqlHandler := graphql.NewHandler(accountHandler)
+	accountHandler := account.NewHandler(userRepo, verificationRepo, identityRepo, bankRepo, sessionRepo, refreshTokenRepo, paystackClient, paystackClient, nameMatcher, tokenManager, hasher)
+	graphqlHandler := graphql.NewHandler(accountHandler, bankHandler)
 
 	mux := http.NewServeMux()
 	graphqlHandler.SetupRoutes(mux)
@@ -69,18 +136,62 @@ func TestMain(m *testing.M) {
 	// run the tests
 	code := m.Run()
 
-	ctx, _ := context.WithTimeout(context.Background(), 1*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("unable to shutdown server gracefully: %v", err)
 	}
+	cancel()
+
+	if fakePaystackServer != nil {
+		fakePaystackServer.Close()
+	}
 
 	os.Exit(code)
 }
 
-func deleteAllUsers() error {
-	return userRepo.DeleteAllUsers()
+// resetDatabase deletes every row the tests create, children before the rows they reference
+func resetDatabase() error {
+	deletes := []func() error{
+		userRepo.DeleteAllUserBankAccounts,
+		verificationRepo.DeleteAllVerificationAttempts,
+		identityRepo.DeleteAllUserIdentities,
+		refreshTokenRepo.DeleteAllRefreshTokens,
+		sessionRepo.DeleteAllSessions,
+		userRepo.DeleteAllUsers,
+	}
+
+	for _, deleteAll := range deletes {
+		if err := deleteAll(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issueExpiredToken signs a token for session that expired a minute ago
+func issueExpiredToken(session *app.Session) (string, error) {
+	key := authConfig.SigningKeys[0]
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{
+		SessionID: session.ID,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   session.UserID,
+			Issuer:    authConfig.Issuer,
+			IssuedAt:  time.Now().Add(-time.Hour).Unix(),
+			ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+		},
+	})
+	token.Header["kid"] = key.ID
+	return token.SignedString([]byte(key.Secret))
 }
 
-func deleteAllUserBankAccounts() error {
-	return userRepo.DeleteAllUserBankAccounts()
+// login starts a session for user the way the login mutation does and returns its token
+func login(user *app.User) (string, error) {
+	session := &app.Session{UserID: user.ID, ExpiresAt: time.Now().Add(tokenManager.Expiry())}
+	err := sessionRepo.CreateSession(context.Background(), session)
+	if err != nil {
+		return "", err
+	}
+
+	token, _, err := tokenManager.Issue(session)
+	return token, err
 }
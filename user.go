@@ -14,14 +14,16 @@ import (
 )
 
 type User struct {
-	ID        string         `json:"id" gorm:"default:gen_random_uuid()"`
-	Email     string         `json:"email"`
-	Name      string         `json:"name"`
-	Password  *password.Hash `json:"password"`
-	Verified  bool           `json:"verified"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt *time.Time     `json:"deleted_at"`
+	ID          string         `json:"id" gorm:"default:gen_random_uuid()"`
+	Email       string         `json:"email"`
+	Name        string         `json:"name"`
+	Password    *password.Hash `json:"password"`
+	Verified    bool           `json:"verified"`
+	IsAdmin     bool           `json:"is_admin"`
+	DateOfBirth *time.Time     `json:"date_of_birth" gorm:"type:date"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   *time.Time     `json:"deleted_at"`
 }
 
 type EmailAddress string
@@ -47,11 +49,18 @@ func (e *EmailAddress) UnmarshalGQL(v interface{}) error {
 	return nil
 }
 
+const (
+	BankAccountStatusVerified = "verified"
+	BankAccountStatusPending  = "pending"
+	BankAccountStatusRejected = "rejected"
+)
+
 type UserBankAccount struct {
 	ID          string       `json:"id" gorm:" default:gen_random_uuid()"`
 	UserID      string       `json:"user_id"`
 	User        *User        `json:"user"`
 	BankAccount *BankAccount `json:"bank_account"`
+	Status      string       `json:"status"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 	DeletedAt   *time.Time   `json:"deleted_at"`
@@ -77,9 +86,14 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, user *User) error
 	UpdateUser(ctx context.Context, user *User) error
 	FindUserByID(ctx context.Context, id string) (*User, error)
+	FindUserByEmail(ctx context.Context, email string) (*User, error)
 
 	SaveUserBankAccount(ctx context.Context, account *UserBankAccount) error
+	UpdateUserBankAccount(ctx context.Context, account *UserBankAccount) error
 	FindUserBankAccount(ctx context.Context, bankCode string, accountNumber string) (*UserBankAccount, error)
+	FindUserBankAccountByID(ctx context.Context, id string) (*UserBankAccount, error)
+	FindUserBankAccountsByStatus(ctx context.Context, status string) ([]*UserBankAccount, error)
+	HasVerifiedUserBankAccount(ctx context.Context, userID string) (bool, error)
 	DeleteAllUserBankAccounts() error
 	DeleteAllUsers() error
 }
@@ -0,0 +1,36 @@
+package buycoin_challenge2
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DecisionApproved         = "approved"
+	DecisionNeedsReview      = "needs_review"
+	DecisionRejected         = "rejected"
+	DecisionResolutionFailed = "resolution_failed"
+)
+
+// VerificationAttempt records how the account name a user submitted compared with the name
+// the bank returned, so rejected attempts can be explained later
+type VerificationAttempt struct {
+	ID            string    `json:"id" gorm:"default:gen_random_uuid()"`
+	UserID        string    `json:"user_id"`
+	BankCode      string    `json:"bank_code"`
+	AccountNumber string    `json:"account_number"`
+	SubmittedName string    `json:"submitted_name"`
+	ResolvedName  string    `json:"resolved_name"`
+	Provider      string    `json:"provider"`
+	Strategy      string    `json:"strategy"`
+	Score         float64   `json:"score"`
+	Distance      int       `json:"distance"`
+	Decision      string    `json:"decision"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type VerificationRepository interface {
+	SaveVerificationAttempt(ctx context.Context, attempt *VerificationAttempt) error
+	FindVerificationAttemptsByUserID(ctx context.Context, userID string) ([]*VerificationAttempt, error)
+	DeleteAllVerificationAttempts() error
+}